@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"strconv"
 	"unicode"
 
 	"github.com/askeladdk/prattle"
@@ -27,59 +26,47 @@ const (
 	answer
 )
 
-var rune2name = map[rune]int{
-	'+': plus,
-	'-': minus,
-	'*': star,
-	'/': slash,
-	'^': caret,
-	'ˆ': caret,
-	'%': modulo,
-	'(': leftPar,
-	')': rightPar,
-	'π': pi,
-	'!': bang,
-	'√': squareRoot,
-}
+var operators = prattle.NewOperatorSet(map[string]int{
+	"+":   plus,
+	"-":   minus,
+	"*":   star,
+	"/":   slash,
+	"^":   caret,
+	"ˆ":   caret,
+	"%":   modulo,
+	"(":   leftPar,
+	")":   rightPar,
+	"π":   pi,
+	"!":   bang,
+	"√":   squareRoot,
+	"ans": answer,
+})
 
-func scan(s *prattle.Scanner) int {
-	s.ExpectAny(unicode.IsSpace)
-	s.Skip()
+type calculator struct {
+	stack   []float64
+	answer  float64
+	scanErr error
+}
 
-	r := s.Peek()
-	switch {
-	case s.Done():
-		return 0
-	case s.Expect('a'):
-		if s.Expect('n') {
-			if s.Expect('s') {
-				return answer
-			}
-		}
-	case s.ExpectOne(unicode.IsDigit):
-		s.ExpectAny(unicode.IsDigit)
-		if s.Expect('.') {
-			s.ExpectAny(unicode.IsDigit)
+func (c *calculator) scan(s *prattle.Scanner) int {
+	if kind, err := s.ScanNumber(prattle.DecimalNumbers); err != nil {
+		// keep the first error, which points at the offending rune
+		if c.scanErr == nil {
+			c.scanErr = err
 		}
+		return -1
+	} else if kind != prattle.NotNumber {
 		return number
-	case s.Expect('.'):
-		if s.ExpectOne(unicode.IsDigit) {
-			s.ExpectAny(unicode.IsDigit)
-			return number
-		}
-	case s.ExpectOne(prattle.OneOf("+-*/^ˆ%()π!√")):
-		return rune2name[r]
+	} else if s.Done() {
+		return 0
+	} else if kind := s.ScanOperator(operators); kind != 0 {
+		return kind
 	}
 
 	s.Advance()
 	return -1
 }
 
-type calculator struct {
-	stack  []float64
-	answer float64
-}
-
 func (c *calculator) pop() (v float64) {
 	n := len(c.stack)
 	if n == 0 {
@@ -94,7 +81,7 @@ func (c *calculator) push(v float64) {
 }
 
 func (c *calculator) number(p *prattle.Parser, t prattle.Token) error {
-	v, err := strconv.ParseFloat(t.Text, 64)
+	v, err := prattle.DecimalNumbers.ParseFloat(t.Text)
 	if err != nil {
 		return err
 	}
@@ -259,14 +246,17 @@ func (c *calculator) Precedence(kind int) int {
 }
 
 func (c *calculator) ParseError(t prattle.Token) error {
-	if t.Kind == 0 {
+	if c.scanErr != nil {
+		return c.scanErr
+	} else if t.Kind == 0 {
 		return fmt.Errorf("incomplete equation")
 	}
 	return fmt.Errorf("i do not understand '%s'", t.Text)
 }
 
 func (c *calculator) calculate(expr string) (v float64, err error) {
-	s := prattle.Scanner{Scan: scan}
+	c.scanErr = nil
+	s := prattle.Scanner{Scan: c.scan, Trivia: &prattle.Trivia{Space: unicode.IsSpace}}
 	p := prattle.Parser{Driver: c}
 	err = p.Init(s.InitWithString(expr)).Parse(0)
 	if err == nil && p.Peek().Kind != 0 {
@@ -0,0 +1,140 @@
+package prattle
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// asciiSet is a bitset of ASCII runes.
+type asciiSet [2]uint64
+
+func (a *asciiSet) add(r rune) {
+	a[r>>6] |= 1 << uint(r&63)
+}
+
+func (a *asciiSet) contains(r rune) bool {
+	return r >= 0 && r < utf8.RuneSelf && a[r>>6]&(1<<uint(r&63)) != 0
+}
+
+// runeRange is an inclusive range of runes.
+type runeRange struct {
+	lo, hi rune
+}
+
+// class is a set of runes that looks up ASCII runes in a bitset.
+type class struct {
+	ascii  asciiSet
+	ranges []runeRange
+}
+
+func (c *class) add(lo, hi rune) {
+	for ; lo <= hi && lo < utf8.RuneSelf; lo++ {
+		c.ascii.add(lo)
+	}
+	if lo <= hi {
+		c.ranges = append(c.ranges, runeRange{lo, hi})
+	}
+}
+
+func (c *class) accept() AcceptFunc {
+	if len(c.ranges) == 0 {
+		ascii := c.ascii
+		return ascii.contains
+	}
+	return c.contains
+}
+
+func (c *class) contains(r rune) bool {
+	if r < utf8.RuneSelf {
+		return c.ascii.contains(r)
+	}
+	for _, x := range c.ranges {
+		if x.lo <= r && r <= x.hi {
+			return true
+		}
+	}
+	return false
+}
+
+// OneOf returns an AcceptFunc that reports whether a rune appears in chars.
+func OneOf(chars string) AcceptFunc {
+	var c class
+	for _, r := range chars {
+		c.add(r, r)
+	}
+	return c.accept()
+}
+
+// Class returns an AcceptFunc that accepts the runes
+// described by spec in the syntax of a regular expression character class
+// without the brackets, such as "A-Za-z_$".
+// A '-' that begins or ends spec is accepted literally.
+// ASCII runes are looked up in a bitset.
+func Class(spec string) AcceptFunc {
+	var c class
+	runes := []rune(spec)
+	for i := 0; i < len(runes); i++ {
+		if lo := runes[i]; i+2 < len(runes) && runes[i+1] == '-' {
+			c.add(lo, runes[i+2])
+			i += 2
+		} else {
+			c.add(lo, lo)
+		}
+	}
+	return c.accept()
+}
+
+// InRange returns an AcceptFunc that accepts the runes from lo to hi inclusive.
+func InRange(lo, hi rune) AcceptFunc {
+	return func(r rune) bool {
+		return lo <= r && r <= hi
+	}
+}
+
+// InTable returns an AcceptFunc that accepts the runes in table.
+func InTable(table *unicode.RangeTable) AcceptFunc {
+	return func(r rune) bool {
+		return unicode.Is(table, r)
+	}
+}
+
+// Not returns an AcceptFunc that accepts the runes that accept does not accept.
+// Scanner.ExpectAny stops at the end of input, so ExpectAny(Not(accept))
+// scans up to the next rune that accept accepts.
+func Not(accept AcceptFunc) AcceptFunc {
+	return func(r rune) bool {
+		return !accept(r)
+	}
+}
+
+// Or returns an AcceptFunc that accepts the runes that any of accepts accepts.
+func Or(accepts ...AcceptFunc) AcceptFunc {
+	return func(r rune) bool {
+		for _, accept := range accepts {
+			if accept(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// And returns an AcceptFunc that accepts the runes that all of accepts accept.
+func And(accepts ...AcceptFunc) AcceptFunc {
+	return func(r rune) bool {
+		for _, accept := range accepts {
+			if !accept(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Except returns an AcceptFunc that accepts the runes
+// that accept accepts and except does not accept.
+func Except(accept, except AcceptFunc) AcceptFunc {
+	return func(r rune) bool {
+		return accept(r) && !except(r)
+	}
+}
@@ -0,0 +1,55 @@
+package prattle
+
+import (
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestAccept(t *testing.T) {
+	for _, testCase := range []struct {
+		Name   string
+		Accept AcceptFunc
+		Yes    string
+		No     string
+	}{
+		{"OneOf", OneOf("+-*/√"), "+-*/√", "a≠\x00\x7f\u0080"},
+		{"Class", Class("A-Za-z_$"), "AZaz_$", "09-@[`{\u0080é"},
+		{"ClassDash", Class("-a-c+-"), "-abc+", "d,"},
+		{"ClassUnicode", Class("a-zα-ω"), "azαλω", "AΩ"},
+		{"InRange", InRange('0', '9'), "0189", "/:a"},
+		{"InTable", InTable(unicode.Greek), "αΩ", "a1"},
+		{"Not", Not(InRange('0', '9')), "a/", "09"},
+		{"Or", Or(InRange('0', '9'), OneOf("_")), "0_9", "a-"},
+		{"And", And(unicode.IsLetter, unicode.IsUpper), "AΩ", "a1ω"},
+		{"Except", Except(unicode.IsLetter, OneOf("xX")), "aZé", "xX1"},
+		{"Empty", Class(""), "", "a\x00"},
+	} {
+		for _, r := range testCase.Yes {
+			if !testCase.Accept(r) {
+				t.Errorf("%s: %q not accepted", testCase.Name, r)
+			}
+		}
+		for _, r := range testCase.No {
+			if testCase.Accept(r) {
+				t.Errorf("%s: %q accepted", testCase.Name, r)
+			}
+		}
+	}
+
+	if OneOf("a")(-1) || Class("a-z")(utf8.RuneError) {
+		t.Error()
+	}
+
+	// Not accepts the zero rune that Peek returns at the end of input
+	s := Scanner{Scan: Lexer(ClassRule(1, Not(OneOf(" "))), ClassRule(0, OneOf(" ")))}
+	s.InitWithString("ab cd")
+	for _, x := range []string{"ab", "cd", ""} {
+		if tok, _ := s.Next(); tok.Text != x {
+			t.Fatal(tok)
+		}
+	}
+	if s.ExpectOne(Not(OneOf(" "))) {
+		t.Error("ExpectOne accepted the end of input")
+	}
+}
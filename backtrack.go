@@ -0,0 +1,104 @@
+package prattle
+
+// Checkpoint is a position in the token stream created by Parser.Mark.
+// The zero Checkpoint is always released.
+type Checkpoint struct {
+	id    int
+	pos   int
+	token Token
+	err   error
+}
+
+// Restorer is implemented by Drivers that must undo changes to their state
+// when TryParse backtracks.
+type Restorer interface {
+	// Save returns a snapshot of the state of the Driver.
+	Save() interface{}
+
+	// Restore restores the state of the Driver to a snapshot returned by Save.
+	Restore(snapshot interface{})
+}
+
+// memoKey identifies a call to Parse by token position and precedence.
+type memoKey struct {
+	pos   int
+	least int
+}
+
+// Mark returns a checkpoint of the last read token that can be restored with Rewind.
+// The Parser retains the tokens read after it until it is released with Release.
+// The first call wraps the Iterator in a Lookahead unless it already is one.
+func (p *Parser) Mark() Checkpoint {
+	p.markID++
+	p.marks = append(p.marks, p.markID)
+	return Checkpoint{
+		id:    p.markID,
+		pos:   p.lookahead().Mark(),
+		token: p.token,
+		err:   p.err,
+	}
+}
+
+// Rewind restores the Parser to a checkpoint created by Mark.
+// It panics if the checkpoint has been released.
+func (p *Parser) Rewind(c Checkpoint) {
+	if p.held(c) < 0 {
+		panic("prattle: Rewind called with released checkpoint")
+	}
+	p.lookahead().Rewind(c.pos)
+	p.token = c.token
+	p.err = c.err
+}
+
+// Release releases a checkpoint created by Mark.
+// Every checkpoint must be released for the Parser to drop the tokens it retains.
+// Releasing a checkpoint that has already been released does nothing.
+func (p *Parser) Release(c Checkpoint) {
+	if i := p.held(c); i >= 0 {
+		p.marks = append(p.marks[:i], p.marks[i+1:]...)
+		p.lookahead().Release()
+	}
+}
+
+// held returns the index of a checkpoint in the marks held by the Parser,
+// or -1 if it has been released.
+func (p *Parser) held(c Checkpoint) int {
+	for i := len(p.marks) - 1; i >= 0; i-- {
+		if p.marks[i] == c.id {
+			return i
+		}
+	}
+	return -1
+}
+
+// TryParse calls f speculatively.
+// If f returns an error, TryParse rewinds the Parser to the last read token
+// and restores the state of the Driver if it implements Restorer.
+// It returns the error of f.
+func (p *Parser) TryParse(f ParseFunc, t Token) error {
+	c := p.Mark()
+	defer p.Release(c)
+
+	r, isRestorer := p.Driver.(Restorer)
+	var snapshot interface{}
+	if isRestorer {
+		snapshot = r.Save()
+	}
+
+	err := f(p, t)
+	if err != nil {
+		p.Rewind(c)
+		if isRestorer {
+			r.Restore(snapshot)
+		}
+	}
+	return err
+}
+
+// memoized returns the error of an earlier failed call to Parse
+// at the last read token with the same precedence.
+func (p *Parser) memoized(least int) (memoKey, error) {
+	la := p.lookahead()
+	key := memoKey{la.offset + la.cursor, least}
+	return key, p.memo[key]
+}
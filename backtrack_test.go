@@ -0,0 +1,204 @@
+package prattle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestLookaheadRewind(t *testing.T) {
+	var tokens []Token
+	for i := 1; i <= 20; i++ {
+		tokens = append(tokens, Token{Kind: i})
+	}
+
+	var la Lookahead
+	la.Init(SliceIterator(tokens))
+
+	la.Next()
+	m1 := la.Mark()
+	for i := 0; i < 10; i++ {
+		la.Next()
+	}
+	m2 := la.Mark()
+	la.Next()
+	la.Rewind(m1)
+	if tok, _ := la.Next(); tok.Kind != 2 {
+		t.Fatal(tok)
+	}
+	la.Rewind(m2)
+	if tok, _ := la.Next(); tok.Kind != 12 {
+		t.Fatal(tok)
+	}
+
+	la.Release()
+	la.Release()
+	la.Release()
+	if la.count != 0 {
+		t.Error(la.count)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	la.Rewind(m1)
+}
+
+func TestParserRelease(t *testing.T) {
+	var tokens []Token
+	for i := 1; i <= 5; i++ {
+		tokens = append(tokens, Token{Kind: i})
+	}
+
+	p := Parser{Driver: &testDriver{}}
+	p.Init(SliceIterator(tokens))
+
+	c1 := p.Mark()
+	p.Advance()
+	c2 := p.Mark()
+	p.Advance()
+
+	// releasing c2 twice must not release c1
+	p.Release(c2)
+	p.Release(c2)
+	p.Advance()
+	p.Rewind(c1)
+	if p.Peek().Kind != 1 {
+		t.Fatal(p.Peek())
+	}
+
+	p.Release(c1)
+	p.Release(Checkpoint{})
+	if la := p.lookahead(); la.marks != 0 || la.cursor != 0 {
+		t.Error(la.marks, la.cursor)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	p.Rewind(c2)
+}
+
+const (
+	btIdent int = 1 + iota
+	btLeft
+	btRight
+	btComma
+	btArrow
+)
+
+type btDriver struct {
+	out    []string
+	idents int
+}
+
+func (d *btDriver) Infix(int) ParseFunc      { return nil }
+func (d *btDriver) Precedence(int) int       { return 0 }
+func (d *btDriver) ParseError(t Token) error { return errors.New("unexpected " + t.Text) }
+func (d *btDriver) Save() interface{}        { return len(d.out) }
+func (d *btDriver) Restore(s interface{})    { d.out = d.out[:s.(int)] }
+
+func (d *btDriver) Prefix(kind int) ParseFunc {
+	switch kind {
+	case btIdent:
+		return d.ident
+	case btLeft:
+		return d.paren
+	}
+	return nil
+}
+
+func (d *btDriver) ident(p *Parser, t Token) error {
+	d.idents++
+	if t.Text == "bad" {
+		return errors.New("bad")
+	}
+	d.out = append(d.out, t.Text)
+	return nil
+}
+
+// paren parses either a lambda (a, b) => c or a parenthesized expression (a).
+func (d *btDriver) paren(p *Parser, t Token) error {
+	if err := p.TryParse(d.lambda, t); err == nil {
+		return nil
+	} else if err := p.Parse(0); err != nil {
+		return err
+	} else if !p.Expect(btRight) {
+		return errors.New("expected )")
+	}
+	return nil
+}
+
+func (d *btDriver) lambda(p *Parser, t Token) error {
+	for p.Peek().Kind == btIdent {
+		d.out = append(d.out, "param "+p.Peek().Text)
+		p.Advance()
+		if !p.Expect(btComma) {
+			break
+		}
+	}
+	if !p.Expect(btRight) || !p.Expect(btArrow) {
+		return errors.New("not a lambda")
+	}
+	d.out = append(d.out, "lambda")
+	return p.Parse(0)
+}
+
+func TestTryParse(t *testing.T) {
+	s := Scanner{Scan: Lexer(
+		ClassRule(0, unicode.IsSpace),
+		ClassRule(btIdent, unicode.IsLetter),
+		LiteralRule(btLeft, "("),
+		LiteralRule(btRight, ")"),
+		LiteralRule(btComma, ","),
+		LiteralRule(btArrow, "=>"),
+	)}
+
+	for _, testCase := range []struct {
+		Source string
+		Out    string
+	}{
+		{"(a, b) => c", "param a|param b|lambda|c"},
+		{"(a)", "a"},
+		{"((a) => (b))", "param a|lambda|b"},
+		{"(a) => (b, c) => d", "param a|lambda|param b|param c|lambda|d"},
+	} {
+		var d btDriver
+		p := Parser{Driver: &d}
+		if err := p.Init(s.InitWithString(testCase.Source)).Parse(0); err != nil {
+			t.Error(testCase.Source, err)
+		} else if out := strings.Join(d.out, "|"); out != testCase.Out {
+			t.Error(testCase.Source, out)
+		} else if p.Peek().Kind != 0 {
+			t.Error(testCase.Source, p.Peek())
+		}
+	}
+
+	t.Run("Memoize", func(t *testing.T) {
+		for _, memoize := range []bool{false, true} {
+			var d btDriver
+			p := Parser{Driver: &d, Memoize: memoize}
+			p.Init(s.InitWithString("(bad"))
+			if _, ok := p.iter.(*Lookahead); ok != memoize {
+				t.Fatal(memoize, ok)
+			}
+
+			try := func(p *Parser, t Token) error {
+				return p.Parse(0)
+			}
+
+			if err := p.TryParse(try, p.Peek()); err == nil {
+				t.Fatal("expected error")
+			} else if err := p.Parse(0); err == nil {
+				t.Fatal("expected error")
+			} else if memoize && d.idents != 1 || !memoize && d.idents != 2 {
+				t.Error(memoize, d.idents)
+			}
+		}
+	})
+}
@@ -89,3 +89,31 @@ func BenchmarkScannerWithReader(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkScannerWithReaderBytes(b *testing.B) {
+	b.ReportAllocs()
+	rng := rand.New(rand.NewSource(0))
+	words := genWords(2048, rng)
+	s := Scanner{Scan: scanWords}
+	r := strings.NewReader(words)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Reset(words)
+		s.InitWithReader(r)
+		for _, _, ok := s.NextBytes(); ok; _, _, ok = s.NextBytes() {
+		}
+	}
+}
+
+func BenchmarkScannerWithBytes(b *testing.B) {
+	b.ReportAllocs()
+	rng := rand.New(rand.NewSource(0))
+	words := []byte(genWords(2048, rng))
+	s := Scanner{Scan: scanWords}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.InitWithBytes(words)
+		for _, _, ok := s.NextBytes(); ok; _, _, ok = s.NextBytes() {
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package prattle
+
+import "unicode/utf8"
+
+// ColumnFunc computes the zero-based column that follows a rune
+// of size bytes at the zero-based column col.
+// It is not called for line terminators, which reset the column to zero.
+type ColumnFunc func(col int, r rune, size int) int
+
+// RuneColumns counts columns in runes. This is the default.
+func RuneColumns(col int, r rune, size int) int {
+	return col + 1
+}
+
+// ByteColumns counts columns in bytes.
+func ByteColumns(col int, r rune, size int) int {
+	return col + size
+}
+
+// UTF16Columns counts columns in UTF-16 code units,
+// as used by the Language Server Protocol.
+func UTF16Columns(col int, r rune, size int) int {
+	if r > 0xffff && r <= utf8.MaxRune {
+		return col + 2
+	}
+	return col + 1
+}
+
+// TabColumns returns a ColumnFunc that counts columns in runes
+// and expands tabs to the next multiple of width.
+func TabColumns(width int) ColumnFunc {
+	return func(col int, r rune, size int) int {
+		if r == '\t' && width > 0 {
+			return (col/width + 1) * width
+		}
+		return col + 1
+	}
+}
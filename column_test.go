@@ -0,0 +1,46 @@
+package prattle
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestColumns(t *testing.T) {
+	scan := func(s *Scanner) int {
+		s.ExpectAny(unicode.IsSpace)
+		s.Skip()
+		if s.Done() {
+			return 0
+		}
+		s.Advance()
+		return 1
+	}
+
+	source := "a\t€😀b\n\tc"
+
+	for _, testCase := range []struct {
+		Name    string
+		Columns ColumnFunc
+		Expect  []int
+	}{
+		{"Default", nil, []int{1, 3, 4, 5, 2}},
+		{"Runes", RuneColumns, []int{1, 3, 4, 5, 2}},
+		{"Bytes", ByteColumns, []int{1, 3, 6, 10, 2}},
+		{"UTF16", UTF16Columns, []int{1, 3, 4, 6, 2}},
+		{"Tabs", TabColumns(8), []int{1, 9, 10, 11, 9}},
+		{"Tabs4", TabColumns(4), []int{1, 5, 6, 7, 5}},
+	} {
+		t.Run(testCase.Name, func(t *testing.T) {
+			s := Scanner{
+				Scan:    scan,
+				Columns: testCase.Columns,
+			}
+			s.InitWithString(source)
+			for _, x := range testCase.Expect {
+				if tok, _ := s.Next(); tok.Column != x {
+					t.Fatal(tok)
+				}
+			}
+		})
+	}
+}
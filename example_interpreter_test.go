@@ -17,9 +17,6 @@ const (
 )
 
 func testScan(s *prattle.Scanner) int {
-	s.ExpectAny(unicode.IsSpace)
-	s.Skip()
-
 	switch {
 	case s.Done():
 		return 0
@@ -128,9 +125,16 @@ func Example_interpreter() {
 		idents: make(map[string]int),
 	}
 
-	source := "a = 1;\nb = 2;\nc = a+b+b+a;\n"
+	source := "a = 1;\nb = 2; // two\nc = a+b+b+a;\n"
 
-	s := prattle.Scanner{Scan: testScan}
+	// Skip whitespace and comments before scanning each token.
+	s := prattle.Scanner{
+		Scan: testScan,
+		Trivia: &prattle.Trivia{
+			Space:        unicode.IsSpace,
+			LineComments: []string{"//"},
+		},
+	}
 	p := prattle.Parser{Driver: &c}
 	p.Init(s.InitWithString(source))
 
@@ -150,3 +154,40 @@ func Example_interpreter() {
 	// Output:
 	// c = 6
 }
+
+// This example demonstrates terminating the statements of the interpreter by line breaks.
+func Example_semicolons() {
+	c := testDriver{
+		idents: make(map[string]int),
+	}
+
+	source := "a = 1\nb = 2 // two\nc = a+b+\n\tb+a"
+
+	s := prattle.Scanner{
+		Scan: testScan,
+		Trivia: &prattle.Trivia{
+			Space:        unicode.IsSpace,
+			LineComments: []string{"//"},
+		},
+	}
+
+	// Insert a semicolon after every line that ends with an identifier or a number.
+	sc := prattle.Semicolons{Semicolon: ksemicolon, Rule: prattle.GoSemicolons}
+	p := prattle.Parser{Driver: &c}
+	p.Init(sc.Init(s.InitWithString(source)))
+
+	for p.Peek().Kind != 0 {
+		if err := p.Parse(ksemicolon); err != nil {
+			fmt.Println(err)
+			return
+		} else if !p.Expect(ksemicolon) {
+			fmt.Println("expected semicolon")
+			return
+		}
+	}
+
+	fmt.Printf("c = %d\n", c.idents["c"])
+
+	// Output:
+	// c = 6
+}
@@ -0,0 +1,216 @@
+package prattle
+
+import (
+	"sort"
+	"sync"
+)
+
+// Pos is a compact encoding of a position in a FileSet.
+// It can be converted to a Position with FileSet.Position or File.Position.
+// The zero value NoPos is not a valid position.
+type Pos int
+
+// NoPos is the zero value of Pos. It does not belong to any file.
+const NoPos Pos = 0
+
+// IsValid reports whether a Pos is valid.
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// File is an input that has been added to a FileSet.
+// It records the offsets at which lines start so that
+// Pos values can be converted to Positions.
+type File struct {
+	name string
+	base int
+	size int
+
+	mu      sync.Mutex
+	lines   []int
+	columns []fileColumn
+}
+
+// fileColumn records the column at an offset.
+// Up to the next fileColumn, columns advance by one per byte.
+type fileColumn struct {
+	offset int
+	column int
+}
+
+// Name returns the filename of the File.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Base returns the Pos of the first byte of the File.
+func (f *File) Base() int {
+	return f.base
+}
+
+// Size returns the size of the File in bytes.
+func (f *File) Size() int {
+	return f.size
+}
+
+// AddLine records that a line starts at the byte offset.
+// Offsets that are not greater than the last recorded offset are ignored,
+// so a Scanner may add the same line more than once.
+// Scanner calls AddLine while it scans the input.
+func (f *File) AddLine(offset int) {
+	f.mu.Lock()
+	if n := len(f.lines); offset > f.lines[n-1] && offset <= f.size {
+		f.lines = append(f.lines, offset)
+	}
+	f.mu.Unlock()
+}
+
+// addColumn records the column at the byte offset.
+// The Scanner calls it after runes whose width in columns differs from their size in bytes,
+// so that Position reports the same columns as the Scanner.
+func (f *File) addColumn(offset, column int) {
+	f.mu.Lock()
+	if n := len(f.columns); (n == 0 || offset > f.columns[n-1].offset) && offset <= f.size {
+		f.columns = append(f.columns, fileColumn{offset, column})
+	}
+	f.mu.Unlock()
+}
+
+// LineCount returns the number of lines recorded so far.
+func (f *File) LineCount() int {
+	f.mu.Lock()
+	n := len(f.lines)
+	f.mu.Unlock()
+	return n
+}
+
+// Pos returns the Pos of a byte offset in the File.
+func (f *File) Pos(offset int) Pos {
+	return Pos(f.base + offset)
+}
+
+// Offset returns the byte offset of a Pos in the File.
+func (f *File) Offset(p Pos) int {
+	return int(p) - f.base
+}
+
+// Position converts a Pos to a Position.
+// The column is counted like the Scanner that scanned the File counts columns.
+// Lines and columns are only known up to where the File has been scanned;
+// beyond that, columns are counted in bytes.
+// It returns the zero Position if the Pos is not in the File.
+func (f *File) Position(p Pos) Position {
+	offset := f.Offset(p)
+	if !p.IsValid() || offset < 0 || offset > f.size {
+		return Position{}
+	}
+
+	f.mu.Lock()
+	i := sort.Search(len(f.lines), func(i int) bool {
+		return f.lines[i] > offset
+	}) - 1
+	start, column := f.lines[i], 1
+
+	// continue from the last column recorded on the line
+	if j := sort.Search(len(f.columns), func(j int) bool {
+		return f.columns[j].offset > offset
+	}) - 1; j >= 0 && f.columns[j].offset > start {
+		start, column = f.columns[j].offset, f.columns[j].column
+	}
+	f.mu.Unlock()
+
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     i + 1,
+		Column:   column + offset - start,
+	}
+}
+
+// PosToken is a compact Token that records its position as a Pos in a File
+// instead of a Position. Use FileSet.Position to convert it back.
+type PosToken struct {
+	// Pos is the position of the first character of the token.
+	Pos Pos
+
+	// End is the position immediately after the last character of the token.
+	End Pos
+
+	// Kind identifies the kind of token.
+	Kind int
+
+	// Text is the token value.
+	Text string
+}
+
+// NextPos is like Next but returns a PosToken.
+// The positions are NoPos if File is nil.
+func (s *Scanner) NextPos() (PosToken, bool) {
+	tok, ok := s.Next()
+	ptok := PosToken{Kind: tok.Kind, Text: tok.Text}
+	if s.File != nil {
+		ptok.Pos = s.File.Pos(tok.Offset)
+		ptok.End = s.File.Pos(tok.End.Offset)
+	}
+	return ptok, ok
+}
+
+// FileSet assigns each File a range of Pos values so that
+// a single Pos identifies both the File and the offset within it.
+// It is safe for concurrent use.
+type FileSet struct {
+	mu    sync.RWMutex
+	base  int
+	files []*File
+}
+
+// NewFileSet returns a new FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// AddFile adds a file of size bytes to the FileSet and returns it.
+func (s *FileSet) AddFile(filename string, size int) *File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.base == 0 {
+		s.base = 1
+	}
+
+	f := &File{
+		name:  filename,
+		base:  s.base,
+		size:  size,
+		lines: []int{0},
+	}
+
+	// reserve one more Pos for the end of input
+	s.base += size + 1
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the File that contains a Pos, or nil if there is none.
+func (s *FileSet) File(p Pos) *File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := sort.Search(len(s.files), func(i int) bool {
+		return s.files[i].base > int(p)
+	}) - 1
+
+	if i >= 0 && int(p) <= s.files[i].base+s.files[i].size {
+		return s.files[i]
+	}
+	return nil
+}
+
+// Position converts a Pos to a Position.
+// It returns the zero Position if the Pos is not in the FileSet.
+func (s *FileSet) Position(p Pos) Position {
+	if f := s.File(p); f != nil {
+		return f.Position(p)
+	}
+	return Position{}
+}
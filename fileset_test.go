@@ -0,0 +1,101 @@
+package prattle
+
+import "testing"
+
+func TestFileSet(t *testing.T) {
+	sources := []string{
+		"alpha beta\ngamma\n\ndelta",
+		"epsilon\nzeta",
+	}
+
+	fset := NewFileSet()
+	s := Scanner{Scan: scanWords}
+
+	var pos []Pos
+	var expected []Position
+
+	for i, source := range sources {
+		s.File = fset.AddFile([]string{"a.txt", "b.txt"}[i], len(source))
+		s.InitWithString(source)
+		for tok, ok := s.Next(); ok; tok, ok = s.Next() {
+			pos = append(pos, s.File.Pos(tok.Offset))
+			expected = append(expected, tok.Position)
+		}
+		if s.Pos() != s.File.Pos(len(source)) {
+			t.Fatal("wrong end of input Pos")
+		}
+	}
+
+	if n := fset.File(pos[0]).LineCount(); n != 4 {
+		t.Fatal(n)
+	}
+
+	for i, p := range pos {
+		if x := fset.Position(p); x != expected[i] {
+			t.Fatal(x, expected[i])
+		}
+	}
+
+	if f := fset.File(pos[4]); f == nil || f.Name() != "b.txt" || f.Base() != len(sources[0])+2 || f.Size() != len(sources[1]) {
+		t.Fatal(f)
+	}
+
+	if fset.Position(NoPos).IsValid() || fset.File(Pos(1000)) != nil {
+		t.Fatal("expected invalid position")
+	}
+
+	a, b := fset.File(pos[0]), fset.File(pos[4])
+	if b.Position(a.Pos(3)).IsValid() || a.Position(b.Pos(len(sources[1])+1)).IsValid() {
+		t.Fatal("expected invalid position for a Pos of another File")
+	}
+}
+
+func TestFileSetColumns(t *testing.T) {
+	source := "é x\n\tαβ 😀y\nz\té"
+
+	for _, columns := range []ColumnFunc{nil, ByteColumns, UTF16Columns, TabColumns(4)} {
+		fset := NewFileSet()
+		s := Scanner{Scan: scanWords, Columns: columns}
+		s.File = fset.AddFile("a", len(source))
+
+		var toks []Token
+		for tok, _ := s.InitWithString(source).Next(); tok.Kind != 0; tok, _ = s.Next() {
+			toks = append(toks, tok)
+		}
+
+		s.InitWithString(source)
+		for i, x := range toks {
+			tok, _ := s.NextPos()
+			if tok.Kind != x.Kind || tok.Text != x.Text {
+				t.Fatal(tok)
+			} else if p := fset.Position(tok.Pos); p != x.Position {
+				t.Fatal(i, p, x.Position)
+			} else if p := fset.Position(tok.End); p != x.End {
+				t.Fatal(i, p, x.End)
+			}
+		}
+
+		if tok, ok := s.NextPos(); ok || tok.Pos != s.File.Pos(len(source)) {
+			t.Fatal(tok)
+		}
+	}
+
+	var s Scanner
+	s.Scan = scanWords
+	if tok, _ := s.InitWithString("a").NextPos(); tok.Pos != NoPos || tok.Text != "a" {
+		t.Fatal(tok)
+	}
+}
+
+func TestFileAddLine(t *testing.T) {
+	f := NewFileSet().AddFile("", 10)
+	for _, offset := range []int{3, 3, 2, 6, 11} {
+		f.AddLine(offset)
+	}
+	if f.LineCount() != 3 {
+		t.Fatal(f.LineCount())
+	}
+	if p := f.Position(f.Pos(7)); p.Line != 3 || p.Column != 2 {
+		t.Fatal(p)
+	}
+}
@@ -0,0 +1,77 @@
+package prattle
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// IsXIDStart reports whether r may start an identifier
+// according to the XID_Start property of Unicode Standard Annex #31.
+func IsXIDStart(r rune) bool {
+	if r < utf8.RuneSelf {
+		return 'a' <= lower(r) && lower(r) <= 'z'
+	}
+	return unicode.In(r, unicode.L, unicode.Nl, unicode.Other_ID_Start) &&
+		!unicode.In(r, unicode.Pattern_Syntax, unicode.Pattern_White_Space) &&
+		!isNFKCUnstable(r, true)
+}
+
+// IsXIDContinue reports whether r may continue an identifier
+// according to the XID_Continue property of Unicode Standard Annex #31.
+func IsXIDContinue(r rune) bool {
+	if r < utf8.RuneSelf {
+		return 'a' <= lower(r) && lower(r) <= 'z' || isDecimal(r) || r == '_'
+	}
+	return unicode.In(r, unicode.L, unicode.Nl, unicode.Other_ID_Start,
+		unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue) &&
+		!unicode.In(r, unicode.Pattern_Syntax, unicode.Pattern_White_Space) &&
+		!isNFKCUnstable(r, false)
+}
+
+// isNFKCUnstable reports whether r is one of the few characters
+// that are excluded from XID_Start or XID_Continue
+// because identifiers containing them are not closed under NFKC normalization.
+func isNFKCUnstable(r rune, start bool) bool {
+	switch r {
+	case 0x037a, 0x309b, 0x309c, 0xfdfa, 0xfdfb,
+		0xfe70, 0xfe72, 0xfe74, 0xfe76, 0xfe78, 0xfe7a, 0xfe7c, 0xfe7e:
+		return true
+	case 0x0e33, 0x0eb3, 0xff9e, 0xff9f:
+		return start
+	}
+	return 0xfc5e <= r && r <= 0xfc63
+}
+
+// IsGoIdentStart reports whether r may start a Go identifier.
+func IsGoIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// IsGoIdentContinue reports whether r may continue a Go identifier.
+func IsGoIdentContinue(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ExpectIdent advances the cursor over an identifier
+// that consists of a rune accepted by start
+// followed by any number of runes accepted by cont.
+// Use IsXIDStart and IsXIDContinue to scan Unicode identifiers,
+// or IsGoIdentStart and IsGoIdentContinue to scan Go identifiers.
+func (s *Scanner) ExpectIdent(start, cont AcceptFunc) bool {
+	if !s.ExpectOne(start) {
+		return false
+	}
+	s.ExpectAny(cont)
+	return true
+}
+
+// Keyword looks up the token scanned so far in a table of keywords
+// and returns the associated kind. The lookup does not allocate.
+func (s *Scanner) Keyword(keywords map[string]int) (kind int, ok bool) {
+	if ss, isString := s.reader.(*stringSpanner); isString {
+		kind, ok = keywords[ss.Span()]
+	} else {
+		kind, ok = keywords[string(s.reader.Bytes())]
+	}
+	return kind, ok
+}
@@ -0,0 +1,101 @@
+package prattle
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestXID(t *testing.T) {
+	for _, testCase := range []struct {
+		Rune     rune
+		Start    bool
+		Continue bool
+	}{
+		{'a', true, true},
+		{'Z', true, true},
+		{'_', false, true},
+		{'7', false, true},
+		{'$', false, false},
+		{' ', false, false},
+		{'é', true, true},
+		{'π', true, true},
+		{'́', false, true}, // combining acute accent
+		{'٣', false, true}, // arabic-indic digit three
+		{'℘', true, true},  // script capital p
+		{'·', false, true}, // middle dot
+		{'ⸯ', false, false},
+		{'ͺ', false, false},
+		{'ำ', false, true},
+		{'ﱞ', false, false},
+		{'√', false, false},
+		{'≠', false, false},
+	} {
+		if IsXIDStart(testCase.Rune) != testCase.Start {
+			t.Errorf("IsXIDStart(%U)", testCase.Rune)
+		}
+		if IsXIDContinue(testCase.Rune) != testCase.Continue {
+			t.Errorf("IsXIDContinue(%U)", testCase.Rune)
+		}
+	}
+}
+
+func TestGoIdent(t *testing.T) {
+	if !IsGoIdentStart('_') || IsGoIdentStart('1') || !IsGoIdentContinue('1') || IsGoIdentContinue('́') {
+		t.Error()
+	}
+}
+
+func TestKeyword(t *testing.T) {
+	keywords := map[string]int{
+		"if":    2,
+		"else":  3,
+		"für":   4,
+		"while": 5,
+	}
+
+	scan := func(s *Scanner) int {
+		s.ExpectAny(unicode.IsSpace)
+		s.Skip()
+		switch {
+		case s.Done():
+			return 0
+		case s.ExpectIdent(IsXIDStart, IsXIDContinue):
+			if kind, ok := s.Keyword(keywords); ok {
+				return kind
+			}
+			return 1
+		}
+		s.Advance()
+		return -1
+	}
+
+	source := "if élse für_ für while x́"
+
+	for _, init := range []func(*Scanner) *Scanner{
+		func(s *Scanner) *Scanner { return s.InitWithString(source) },
+		func(s *Scanner) *Scanner { return s.InitWithBytes([]byte(source)) },
+		func(s *Scanner) *Scanner { return s.InitWithReader(strings.NewReader(source)) },
+	} {
+		s := init(&Scanner{Scan: scan})
+		for _, x := range []int{2, 1, 1, 4, 5, 1, 0} {
+			if tok, _, _ := s.NextBytes(); tok.Kind != x {
+				t.Fatal(tok)
+			}
+		}
+	}
+
+	bs, ss := Scanner{Scan: scan}, Scanner{Scan: scan}
+	input := []byte(source)
+	allocs := testing.AllocsPerRun(10, func() {
+		bs.InitWithBytes(input)
+		for _, _, ok := bs.NextBytes(); ok; _, _, ok = bs.NextBytes() {
+		}
+		ss.InitWithString(source)
+		for _, ok := ss.Next(); ok; _, ok = ss.Next() {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("%f allocations", allocs)
+	}
+}
@@ -0,0 +1,146 @@
+package prattle
+
+// The Iterators in this file treat a token of kind zero
+// that is not ok as the end of the token stream, like Scanner does.
+// They forward the Err method of the Iterators they read from,
+// and those that do not buffer tokens forward their ModeStack to Parser.Modes.
+
+// iterErr returns the error reported by the optional Err method of it.
+func iterErr(it Iterator) error {
+	if e, isErrer := it.(interface{ Err() error }); isErrer {
+		return e.Err()
+	}
+	return nil
+}
+
+// atEnd reports whether a token returned by an Iterator ends the token stream.
+func atEnd(tok Token, ok bool) bool {
+	return !ok && tok.Kind == 0
+}
+
+// source forwards the Err method and the ModeStack of the Iterator it reads from.
+type source struct {
+	iter Iterator
+}
+
+func (s source) Err() error {
+	return iterErr(s.iter)
+}
+
+func (s source) modes() (ModeStack, bool) {
+	return iterModes(s.iter)
+}
+
+type sliceIterator struct {
+	tokens []Token
+}
+
+// SliceIterator returns an Iterator that yields the tokens of a slice.
+func SliceIterator(tokens []Token) Iterator {
+	return &sliceIterator{tokens}
+}
+
+func (it *sliceIterator) Next() (Token, bool) {
+	if len(it.tokens) == 0 {
+		return Token{}, false
+	}
+	tok := it.tokens[0]
+	it.tokens = it.tokens[1:]
+	return tok, true
+}
+
+type chanIterator <-chan Token
+
+// ChanIterator returns an Iterator that yields the tokens received from a channel
+// until it is closed.
+func ChanIterator(c <-chan Token) Iterator {
+	return chanIterator(c)
+}
+
+func (c chanIterator) Next() (Token, bool) {
+	tok, ok := <-c
+	return tok, ok
+}
+
+type filterIterator struct {
+	source
+	keep func(Token) bool
+}
+
+// Filter returns an Iterator that yields the tokens of it that keep accepts,
+// for example to drop comment tokens by kind.
+func Filter(it Iterator, keep func(Token) bool) Iterator {
+	return filterIterator{source{it}, keep}
+}
+
+func (it filterIterator) Next() (Token, bool) {
+	for {
+		if tok, ok := it.iter.Next(); atEnd(tok, ok) || it.keep(tok) {
+			return tok, ok
+		}
+	}
+}
+
+type mapIterator struct {
+	source
+	f func(Token) Token
+}
+
+// Map returns an Iterator that yields the tokens of it rewritten by f.
+func Map(it Iterator, f func(Token) Token) Iterator {
+	return mapIterator{source{it}, f}
+}
+
+func (it mapIterator) Next() (Token, bool) {
+	tok, ok := it.iter.Next()
+	if atEnd(tok, ok) {
+		return tok, ok
+	}
+	return it.f(tok), ok
+}
+
+type teeIterator struct {
+	source
+	f func(Token)
+}
+
+// Tee returns an Iterator that yields the tokens of it
+// and passes each of them to f, including the token that ends the stream.
+// It is useful for logging and recording token streams.
+func Tee(it Iterator, f func(Token)) Iterator {
+	return teeIterator{source{it}, f}
+}
+
+func (it teeIterator) Next() (Token, bool) {
+	tok, ok := it.iter.Next()
+	it.f(tok)
+	return tok, ok
+}
+
+type concatIterator struct {
+	iters []Iterator
+}
+
+// Concat returns an Iterator that yields the tokens of iters one after another.
+// An Iterator that ends with an error ends the concatenated stream.
+func Concat(iters ...Iterator) Iterator {
+	return &concatIterator{iters}
+}
+
+func (it *concatIterator) Next() (Token, bool) {
+	for len(it.iters) > 0 {
+		tok, ok := it.iters[0].Next()
+		if !atEnd(tok, ok) || len(it.iters) == 1 || iterErr(it.iters[0]) != nil {
+			return tok, ok
+		}
+		it.iters = it.iters[1:]
+	}
+	return Token{}, false
+}
+
+func (it *concatIterator) Err() error {
+	if len(it.iters) == 0 {
+		return nil
+	}
+	return iterErr(it.iters[0])
+}
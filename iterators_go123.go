@@ -0,0 +1,75 @@
+//go:build go1.23
+// +build go1.23
+
+package prattle
+
+import "iter"
+
+// Seq returns an iter.Seq that yields the tokens of it until the stream ends.
+func Seq(it Iterator) iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for tok, ok := it.Next(); !atEnd(tok, ok); tok, ok = it.Next() {
+			if !yield(tok) {
+				return
+			}
+		}
+	}
+}
+
+// Seq2 returns an iter.Seq2 that yields the tokens of it with a nil error
+// until the stream ends.
+// If the stream was ended by an error, it yields the last token with that error.
+func Seq2(it Iterator) iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		tok, ok := it.Next()
+		for ; !atEnd(tok, ok); tok, ok = it.Next() {
+			if !yield(tok, nil) {
+				return
+			}
+		}
+		if err := iterErr(it); err != nil {
+			yield(tok, err)
+		}
+	}
+}
+
+type pullIterator struct {
+	next func() (Token, error, bool)
+	err  error
+}
+
+// PullSeq converts an iter.Seq into an Iterator, as by iter.Pull.
+// The stop function must be called when the Iterator is no longer needed.
+func PullSeq(seq iter.Seq[Token]) (Iterator, func()) {
+	next, stop := iter.Pull(seq)
+	return &pullIterator{next: func() (Token, error, bool) {
+		tok, ok := next()
+		return tok, nil, ok
+	}}, stop
+}
+
+// PullSeq2 converts an iter.Seq2 into an Iterator, as by iter.Pull2.
+// The first non-nil error ends the token stream and is reported by the Err method.
+// The stop function must be called when the Iterator is no longer needed.
+func PullSeq2(seq iter.Seq2[Token, error]) (Iterator, func()) {
+	next, stop := iter.Pull2(seq)
+	return &pullIterator{next: next}, stop
+}
+
+func (it *pullIterator) Next() (Token, bool) {
+	if it.err != nil {
+		return Token{}, false
+	}
+	tok, err, ok := it.next()
+	if err != nil {
+		it.err = err
+		return Token{Position: tok.Position, End: tok.End}, false
+	} else if !ok {
+		return Token{}, false
+	}
+	return tok, true
+}
+
+func (it *pullIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,58 @@
+//go:build go1.23
+// +build go1.23
+
+package prattle
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	tokens := []Token{{Kind: 1, Text: "a"}, {Kind: 1, Text: "b"}, {Kind: 1, Text: "c"}}
+
+	var texts []string
+	for tok := range Seq(SliceIterator(tokens)) {
+		if texts = append(texts, tok.Text); len(texts) == 2 {
+			break
+		}
+	}
+	if strings.Join(texts, "") != "ab" {
+		t.Error(texts)
+	}
+
+	errBroken := errors.New("broken")
+	texts = texts[:0]
+	var errs []error
+	for tok, err := range Seq2(&erriter{SliceIterator(tokens), errBroken}) {
+		texts = append(texts, tok.Text)
+		errs = append(errs, err)
+	}
+	if strings.Join(texts, "") != "abc" || len(errs) != 4 || errs[2] != nil || errs[3] != errBroken {
+		t.Error(texts, errs)
+	}
+
+	it, stop := PullSeq(slices.Values(tokens))
+	if got := collectTexts(it); got != "a b c" {
+		t.Error(got)
+	}
+	stop()
+
+	seq2 := func(yield func(Token, error) bool) {
+		_ = yield(tokens[0], nil) && yield(Token{}, errBroken) && yield(tokens[1], nil)
+	}
+	it, stop = PullSeq2(seq2)
+	defer stop()
+	if got := collectTexts(it); got != "a" {
+		t.Error(got)
+	} else if err := iterErr(it); err != errBroken {
+		t.Error(err)
+	}
+
+	p := Parser{Driver: &testDriver{}}
+	if p.Init(it).Err() != errBroken {
+		t.Error(p.Err())
+	}
+}
@@ -0,0 +1,112 @@
+package prattle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func collectTexts(it Iterator) string {
+	var texts []string
+	for tok, ok := it.Next(); !atEnd(tok, ok); tok, ok = it.Next() {
+		texts = append(texts, tok.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+func TestIterators(t *testing.T) {
+	tokens := func(texts ...string) []Token {
+		var toks []Token
+		for _, text := range texts {
+			toks = append(toks, Token{Kind: len(text), Text: text})
+		}
+		return toks
+	}
+
+	t.Run("Slice", func(t *testing.T) {
+		if got := collectTexts(SliceIterator(tokens("a", "bb"))); got != "a bb" {
+			t.Error(got)
+		}
+	})
+
+	t.Run("Chan", func(t *testing.T) {
+		c := make(chan Token, 2)
+		c <- Token{Kind: 1, Text: "a"}
+		c <- Token{Kind: 1, Text: "b"}
+		close(c)
+		if got := collectTexts(ChanIterator(c)); got != "a b" {
+			t.Error(got)
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		it := Filter(SliceIterator(tokens("a", "bb", "c", "dd")), func(tok Token) bool {
+			return tok.Kind != 2
+		})
+		if got := collectTexts(it); got != "a c" {
+			t.Error(got)
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		it := Map(SliceIterator(tokens("a", "b")), func(tok Token) Token {
+			tok.Text = strings.ToUpper(tok.Text)
+			return tok
+		})
+		if got := collectTexts(it); got != "A B" {
+			t.Error(got)
+		}
+	})
+
+	t.Run("Concat", func(t *testing.T) {
+		it := Concat(SliceIterator(tokens("a")), SliceIterator(nil), SliceIterator(tokens("b", "c")))
+		if got := collectTexts(it); got != "a b c" {
+			t.Error(got)
+		} else if tok, ok := it.Next(); ok || tok.Kind != 0 {
+			t.Error(tok)
+		}
+
+		errBroken := errors.New("broken")
+		it = Concat(&erriter{SliceIterator(tokens("a")), errBroken}, SliceIterator(tokens("b")))
+		if got := collectTexts(it); got != "a" {
+			t.Error(got)
+		} else if err := iterErr(it); err != errBroken {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Tee", func(t *testing.T) {
+		var recorded []string
+		it := Tee(SliceIterator(tokens("a", "b")), func(tok Token) {
+			recorded = append(recorded, tok.Text)
+		})
+		if got := collectTexts(it); got != "a b" {
+			t.Error(got)
+		} else if strings.Join(recorded, ",") != "a,b," {
+			t.Error(recorded)
+		}
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		errBroken := errors.New("broken")
+		src := &erriter{SliceIterator(nil), errBroken}
+		for _, it := range []Iterator{
+			Filter(src, func(Token) bool { return true }),
+			Map(src, func(tok Token) Token { return tok }),
+			Tee(src, func(Token) {}),
+		} {
+			if err := iterErr(it); err != errBroken {
+				t.Error(err)
+			}
+		}
+	})
+
+	t.Run("Scanner", func(t *testing.T) {
+		s := Scanner{Scan: scanLetters, Trivia: &Trivia{Space: OneOf(" ")}}
+		s.InitWithString("a b 1 c")
+		it := Filter(&s, func(tok Token) bool { return tok.Kind > 0 })
+		if got := collectTexts(it); got != "a b c" {
+			t.Error(got)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package prattle
+
+import "regexp"
+
+// Rule describes a token of a lexer created by Lexer.
+type Rule struct {
+	// Kind is the kind of the token.
+	Kind int
+
+	// Match advances the cursor over the token
+	// and reports whether the input begins with it.
+	// It does not need to restore the cursor when it reports false,
+	// but it must not call Skip.
+	Match func(*Scanner) bool
+}
+
+// LiteralRule returns a Rule that matches the literal text.
+func LiteralRule(kind int, text string) Rule {
+	return Rule{
+		Kind: kind,
+		Match: func(s *Scanner) bool {
+			return text != "" && s.ExpectString(text)
+		},
+	}
+}
+
+// ClassRule returns a Rule that matches one or more runes accepted by accept.
+func ClassRule(kind int, accept AcceptFunc) Rule {
+	return Rule{
+		Kind: kind,
+		Match: func(s *Scanner) bool {
+			if !s.ExpectOne(accept) {
+				return false
+			}
+			s.ExpectAny(accept)
+			return true
+		},
+	}
+}
+
+// RegexpRule returns a Rule that matches the regular expression re
+// at the cursor as by Scanner.ExpectRegexp. Empty matches never win.
+func RegexpRule(kind int, re *regexp.Regexp) Rule {
+	return Rule{
+		Kind: kind,
+		Match: func(s *Scanner) bool {
+			return s.ExpectRegexp(re)
+		},
+	}
+}
+
+// Lexer returns a ScanFunc that scans tokens described by rules.
+// The rule that matches the longest token wins,
+// and of rules that match tokens of the same length the first one wins.
+// Rules with a kind less than one describe trivia, which is skipped.
+// Runes that no rule matches are scanned as tokens of kind -1.
+func Lexer(rules ...Rule) ScanFunc {
+	return func(s *Scanner) int {
+		for !s.Done() {
+			start, best, end := s.Mark(), -1, s.cursor
+			for i, rule := range rules {
+				if rule.Match(s) && s.cursor > end {
+					best, end = i, s.cursor
+				}
+				s.Reset(start)
+			}
+
+			if best < 0 {
+				s.Advance()
+				return -1
+			}
+
+			rules[best].Match(s)
+			if kind := rules[best].Kind; kind > 0 {
+				return kind
+			}
+			s.Skip()
+		}
+		return 0
+	}
+}
@@ -0,0 +1,72 @@
+package prattle
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestLexer(t *testing.T) {
+	const (
+		ident int = 1 + iota
+		keyword
+		number
+		date
+		assign
+		equals
+		arrow
+		comment
+	)
+
+	scan := Lexer(
+		ClassRule(0, unicode.IsSpace),
+		LiteralRule(keyword, "if"),
+		ClassRule(number, isDecimal),
+		ClassRule(ident, IsXIDContinue),
+		RegexpRule(date, regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)),
+		LiteralRule(assign, "="),
+		LiteralRule(equals, "=="),
+		LiteralRule(arrow, "=>"),
+		Rule{Kind: comment, Match: func(s *Scanner) bool {
+			if !s.ExpectString("#") {
+				return false
+			}
+			s.ExpectAny(func(r rune) bool { return r != '\n' })
+			return true
+		}},
+	)
+
+	source := "if iffy = 2024-01-02 ==> 42 $ # done\nx"
+
+	for _, s := range []*Scanner{
+		(&Scanner{Scan: scan}).InitWithString(source),
+		(&Scanner{Scan: scan}).InitWithReader(strings.NewReader(source)),
+	} {
+		var kinds []int
+		var texts []string
+		for tok, ok := s.Next(); ok || tok.Kind < 0; tok, ok = s.Next() {
+			kinds = append(kinds, tok.Kind)
+			texts = append(texts, tok.Text)
+		}
+
+		if got, want := strings.Join(texts, "|"), "if|iffy|=|2024-01-02|==|>|42|$|# done|x"; got != want {
+			t.Errorf("%q", got)
+		}
+
+		if got, want := kinds, []int{keyword, ident, assign, date, equals, -1, number, -1, comment, ident}; len(got) != len(want) {
+			t.Error(got)
+		} else {
+			for i := range got {
+				if got[i] != want[i] {
+					t.Error(got)
+					break
+				}
+			}
+		}
+
+		if s.Line != 2 || s.Column != 2 {
+			t.Error(s.Position)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package prattle
+
+import "strings"
+
+// LineEnding determines which runes the Scanner recognizes as line terminators.
+type LineEnding int
+
+const (
+	// LineEndingLF recognizes only "\n" as line terminator. This is the default.
+	LineEndingLF LineEnding = iota
+
+	// LineEndingCRLF recognizes "\n", "\r\n" and a lone "\r" as line terminators.
+	LineEndingCRLF
+
+	// LineEndingUnicode recognizes all Unicode line terminators:
+	// "\n", "\r\n", "\r", "\v", "\f", U+0085 (NEL),
+	// U+2028 (LINE SEPARATOR) and U+2029 (PARAGRAPH SEPARATOR).
+	LineEndingUnicode
+)
+
+// isTerminator reports whether r is a line terminator under the policy.
+// The "\r" in "\r\n" is considered a terminator here;
+// it is up to the caller to treat the pair as one.
+func (le LineEnding) isTerminator(r rune) bool {
+	switch r {
+	case '\n':
+		return true
+	case '\r':
+		return le != LineEndingLF
+	case '\v', '\f', '\u0085', '\u2028', '\u2029':
+		return le == LineEndingUnicode
+	}
+	return false
+}
+
+// normalize replaces all line terminators in text by "\n".
+func (le LineEnding) normalize(text string) string {
+	switch le {
+	case LineEndingLF:
+		return text
+	case LineEndingCRLF:
+		if !strings.ContainsRune(text, '\r') {
+			return text
+		}
+	default:
+		if !strings.ContainsAny(text, "\r\v\f\u0085\u2028\u2029") {
+			return text
+		}
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(text))
+	for i, r := range text {
+		switch {
+		case r == '\r' && i+1 < len(text) && text[i+1] == '\n':
+		case r != '\n' && le.isTerminator(r):
+			sb.WriteByte('\n')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
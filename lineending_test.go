@@ -0,0 +1,78 @@
+package prattle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineEndings(t *testing.T) {
+	scan := func(s *Scanner) int {
+		switch {
+		case s.Done():
+			return 0
+		case s.Expect('x'):
+			return 1
+		}
+		for !s.Done() && s.Peek() != 'x' {
+			s.Advance()
+		}
+		return 2
+	}
+
+	source := "x\nx\r\nx\rx\u2028x\fx"
+
+	for _, testCase := range []struct {
+		Name        string
+		LineEndings LineEnding
+		Lines       []int
+		Columns     []int
+	}{
+		{"LF", LineEndingLF, []int{1, 2, 3, 3, 3, 3}, []int{1, 1, 1, 3, 5, 7}},
+		{"CRLF", LineEndingCRLF, []int{1, 2, 3, 4, 4, 4}, []int{1, 1, 1, 1, 3, 5}},
+		{"Unicode", LineEndingUnicode, []int{1, 2, 3, 4, 5, 6}, []int{1, 1, 1, 1, 1, 1}},
+	} {
+		t.Run(testCase.Name, func(t *testing.T) {
+			s := Scanner{Scan: scan, LineEndings: testCase.LineEndings}
+			s.InitWithReader(strings.NewReader(source))
+			for i := range testCase.Lines {
+				tok, _ := s.Next()
+				if tok.Line != testCase.Lines[i] || tok.Column != testCase.Columns[i] {
+					t.Fatal(tok)
+				}
+				s.Next()
+			}
+		})
+	}
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	scan := func(s *Scanner) int {
+		if s.Done() {
+			return 0
+		}
+		for !s.Done() {
+			s.Advance()
+		}
+		return 1
+	}
+
+	source := "a\r\nb\rc\nd\u2028e\u0085f\vg"
+
+	for _, testCase := range []struct {
+		LineEndings LineEnding
+		Expect      string
+	}{
+		{LineEndingLF, source},
+		{LineEndingCRLF, "a\nb\nc\nd\u2028e\u0085f\vg"},
+		{LineEndingUnicode, "a\nb\nc\nd\ne\nf\ng"},
+	} {
+		s := Scanner{
+			Scan:                 scan,
+			LineEndings:          testCase.LineEndings,
+			NormalizeLineEndings: true,
+		}
+		if tok, _ := s.InitWithString(source).Next(); tok.Text != testCase.Expect {
+			t.Errorf("%q", tok.Text)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package prattle
+
+// Lookahead is an Iterator that buffers the tokens of another Iterator
+// in a ring buffer, so that any number of upcoming tokens can be inspected.
+// While a mark is held, it also retains the tokens that have been read,
+// so that they can be read again after rewinding to the mark.
+type Lookahead struct {
+	iter   Iterator
+	ring   []lookaheadToken
+	head   int // ring index of the oldest buffered token
+	count  int // number of buffered tokens
+	cursor int // number of buffered tokens that have been read
+	offset int // number of tokens that have been dropped from the buffer
+	marks  int
+}
+
+type lookaheadToken struct {
+	token Token
+	ok    bool
+}
+
+// Init initializes Lookahead with an Iterator and returns it.
+func (la *Lookahead) Init(iter Iterator) *Lookahead {
+	la.iter = iter
+	for i := range la.ring {
+		la.ring[i] = lookaheadToken{}
+	}
+	la.head = 0
+	la.count = 0
+	la.cursor = 0
+	la.offset = 0
+	la.marks = 0
+	return la
+}
+
+// Next implements Iterator.
+func (la *Lookahead) Next() (Token, bool) {
+	if la.cursor == la.count {
+		tok, ok := la.iter.Next()
+		if la.marks == 0 {
+			la.offset++
+			return tok, ok
+		}
+		la.push(tok, ok)
+	}
+
+	t := la.at(la.cursor)
+	la.cursor++
+	la.drop()
+	return t.token, t.ok
+}
+
+// PeekN returns the token n positions ahead without consuming it.
+// PeekN(0) returns the token that the next call to Next returns.
+func (la *Lookahead) PeekN(n int) Token {
+	if n < 0 {
+		n = 0
+	}
+	for la.count-la.cursor <= n {
+		la.push(la.iter.Next())
+	}
+	return la.at(la.cursor + n).token
+}
+
+// Mark returns the position of the token that the next call to Next returns.
+// The tokens read after it are retained until the mark is released.
+func (la *Lookahead) Mark() int {
+	la.marks++
+	return la.offset + la.cursor
+}
+
+// Rewind makes the token at a position returned by Mark
+// the token that the next call to Next returns.
+// It panics if the mark has been released.
+func (la *Lookahead) Rewind(mark int) {
+	i := mark - la.offset
+	if i < 0 || i > la.count {
+		panic("prattle: Rewind called with released mark")
+	}
+	la.cursor = i
+}
+
+// Release releases a mark.
+// The tokens that have been read are dropped once all marks are released.
+func (la *Lookahead) Release() {
+	if la.marks > 0 {
+		la.marks--
+		la.drop()
+	}
+}
+
+// at returns the i-th buffered token.
+func (la *Lookahead) at(i int) lookaheadToken {
+	return la.ring[(la.head+i)&(len(la.ring)-1)]
+}
+
+// push appends a token to the buffer.
+func (la *Lookahead) push(tok Token, ok bool) {
+	if la.count == len(la.ring) {
+		la.grow()
+	}
+	la.ring[(la.head+la.count)&(len(la.ring)-1)] = lookaheadToken{tok, ok}
+	la.count++
+}
+
+// drop drops the tokens that have been read unless a mark is held.
+func (la *Lookahead) drop() {
+	for ; la.marks == 0 && la.cursor > 0; la.cursor-- {
+		la.ring[la.head] = lookaheadToken{}
+		la.head = (la.head + 1) & (len(la.ring) - 1)
+		la.count--
+		la.offset++
+	}
+}
+
+// grow doubles the capacity of the ring buffer,
+// which is always a power of two.
+func (la *Lookahead) grow() {
+	size := 2 * len(la.ring)
+	if size == 0 {
+		size = 8
+	}
+	ring := make([]lookaheadToken, size)
+	for i := 0; i < la.count; i++ {
+		ring[i] = la.at(i)
+	}
+	la.ring = ring
+	la.head = 0
+}
+
+// Err returns the error reported by the Err method
+// of the underlying Iterator, if it has one.
+func (la *Lookahead) Err() error {
+	return iterErr(la.iter)
+}
+
+func (la *Lookahead) modes() (ModeStack, bool) {
+	if la.count > la.cursor {
+		return nil, false
+	}
+	return iterModes(la.iter)
+}
+
+// lookahead returns the Iterator of the Parser as a Lookahead,
+// wrapping it in one if it is not.
+func (p *Parser) lookahead() *Lookahead {
+	la, ok := p.iter.(*Lookahead)
+	if !ok {
+		la = new(Lookahead).Init(p.iter)
+		p.iter = la
+	}
+	return la
+}
+
+// PeekN returns the token n positions ahead of the last read token
+// without advancing the Parser. PeekN(0) is equivalent to Peek.
+// The first call with n greater than zero wraps the Iterator in a Lookahead
+// unless it already is one.
+func (p *Parser) PeekN(n int) Token {
+	if n <= 0 {
+		return p.token
+	}
+	return p.lookahead().PeekN(n - 1)
+}
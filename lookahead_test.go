@@ -0,0 +1,43 @@
+package prattle
+
+import "testing"
+
+func TestLookahead(t *testing.T) {
+	var tokens []Token
+	for i := 1; i <= 20; i++ {
+		tokens = append(tokens, Token{Kind: i})
+	}
+
+	var la Lookahead
+	la.Init(SliceIterator(tokens))
+
+	for i := 1; i <= 21; i++ {
+		// peek further ahead than the initial capacity
+		if tok := la.PeekN(9); i <= 11 && tok.Kind != i+9 || i > 11 && tok.Kind != 0 {
+			t.Fatal(i, tok)
+		} else if tok := la.PeekN(0); i <= 20 && tok.Kind != i {
+			t.Fatal(i, tok)
+		}
+
+		if tok, ok := la.Next(); ok != (i <= 20) || i <= 20 && tok.Kind != i {
+			t.Fatal(i, tok, ok)
+		}
+	}
+}
+
+func TestParserPeekN(t *testing.T) {
+	s := Scanner{Scan: scanLetters, Trivia: &Trivia{Space: OneOf(" ")}}
+	p := Parser{Driver: &testDriver{}}
+	p.Init(s.InitWithString("a b c d"))
+
+	for i, x := range []string{"a", "b", "c", "d", ""} {
+		if tok := p.PeekN(i); tok.Text != x {
+			t.Error(i, tok)
+		}
+	}
+
+	p.Advance()
+	if p.Peek().Text != "b" || p.PeekN(2).Text != "d" {
+		t.Error(p.Peek())
+	}
+}
@@ -0,0 +1,76 @@
+package prattle
+
+import (
+	"errors"
+	"strings"
+)
+
+// takeLeading returns and clears the leading trivia collected by Skip.
+func (s *Scanner) takeLeading() (leading string) {
+	if len(s.leading) > 0 {
+		leading = string(s.leading)
+		s.leading = s.leading[:0]
+	}
+	return leading
+}
+
+// scanTrailing advances the cursor over trivia up to the end of the line.
+// Trivia that spans multiple lines or that must be emitted as a token
+// is left for the next token.
+func (s *Scanner) scanTrailing() {
+	t := s.trivia()
+	if t == nil {
+		return
+	}
+
+	for !s.Done() {
+		if t.Space != nil && t.SpaceKind <= 0 && t.Space(s.peek) {
+			for !s.Done() && t.Space(s.peek) && !s.LineEndings.isTerminator(s.peek) {
+				s.Advance()
+			}
+			if s.LineEndings.isTerminator(s.peek) {
+				return
+			}
+			continue
+		}
+
+		m, line := s.Mark(), s.curline
+		if kind := s.scanTrivia(t); kind != 0 || s.curline != line {
+			s.Reset(m)
+			return
+		}
+	}
+}
+
+// CheckLossless tokenizes source in lossless mode using s
+// and reports an error if the token stream does not reproduce source exactly.
+// Tokens of negative kind do not end the stream.
+// It is intended to verify in tests that a ScanFunc and Trivia
+// do not lose any input.
+func CheckLossless(s *Scanner, source string) error {
+	lossless := s.Lossless
+	defer func() { s.Lossless = lossless }()
+	s.Lossless = true
+	s.InitWithString(source)
+
+	offset := 0
+	for {
+		tok, _ := s.Next()
+		start := offset
+		for _, part := range []string{tok.Leading, tok.Text, tok.Trailing} {
+			if !strings.HasPrefix(source[offset:], part) {
+				return &Error{Position: tok.Position, Err: errors.New("token does not match the input")}
+			}
+			offset += len(part)
+		}
+
+		if tok.Kind == 0 {
+			if offset != len(source) {
+				return &Error{Position: s.Cursor(), Err: errors.New("token stream ended before the end of input")}
+			}
+			return nil
+		} else if offset == start {
+			return &Error{Position: tok.Position, Err: errors.New("scanner made no progress")}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package prattle
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestLossless(t *testing.T) {
+	trivia := Trivia{
+		Space:         unicode.IsSpace,
+		LineComments:  []string{"//"},
+		BlockComments: []BlockComment{{Open: "/*", Close: "*/"}},
+	}
+
+	source := "  // header\nab /* x */ cd // tail\n\n/* multi\nline */ ef  \n"
+
+	s := Scanner{Scan: scanLetters, Trivia: &trivia, Lossless: true}
+	s.InitWithReader(strings.NewReader(source))
+
+	for _, x := range []Token{
+		{Kind: 1, Leading: "  // header\n", Text: "ab", Trailing: " /* x */ "},
+		{Kind: 1, Text: "cd", Trailing: " // tail"},
+		{Kind: 1, Leading: "\n\n/* multi\nline */ ", Text: "ef", Trailing: "  "},
+		{Leading: "\n"},
+	} {
+		if tok, _ := s.Next(); tok.Kind != x.Kind || tok.Leading != x.Leading || tok.Text != x.Text || tok.Trailing != x.Trailing {
+			t.Fatalf("%q %q %q", tok.Leading, tok.Text, tok.Trailing)
+		}
+	}
+
+	if err := CheckLossless(&s, source); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckLossless(t *testing.T) {
+	source := "a = 1;\n  b = 22 ; \n"
+
+	// A ScanFunc that skips whitespace itself is lossless.
+	if err := CheckLossless(&Scanner{Scan: scanWords}, source); err != nil {
+		t.Fatal(err)
+	}
+
+	// A ScanFunc that stops early is not.
+	stop := func(s *Scanner) int {
+		if s.Expect('a') {
+			return 1
+		}
+		return 0
+	}
+	if err := CheckLossless(&Scanner{Scan: stop}, source); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// Neither is a ScanFunc that does not advance.
+	stuck := func(s *Scanner) int {
+		return 1
+	}
+	if err := CheckLossless(&Scanner{Scan: stuck}, source); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,85 @@
+package prattle
+
+// ModeStack is implemented by Iterators that scan tokens
+// differently depending on a mode, such as Scanner.
+type ModeStack interface {
+	// Mode returns the current mode.
+	Mode() int
+
+	// PushMode makes mode the current mode until the matching PopMode.
+	PushMode(mode int)
+
+	// PopMode restores the previous mode and returns the popped mode.
+	PopMode() int
+}
+
+// Mode returns the current mode, which is zero if the mode stack is empty.
+// A ScanFunc may dispatch on it to switch between rule sets,
+// for example to scan string interpolation or embedded templates.
+func (s *Scanner) Mode() int {
+	if n := len(s.modes); n > 0 {
+		return s.modes[n-1]
+	}
+	return 0
+}
+
+// PushMode makes mode the current mode until the matching PopMode.
+func (s *Scanner) PushMode(mode int) {
+	s.modes = append(s.modes, mode)
+}
+
+// PopMode restores the previous mode and returns the popped mode.
+// It returns zero if the mode stack is empty.
+func (s *Scanner) PopMode() int {
+	n := len(s.modes)
+	if n == 0 {
+		return 0
+	}
+	mode := s.modes[n-1]
+	s.modes = s.modes[:n-1]
+	return mode
+}
+
+// Modal returns a ScanFunc that scans with scans[Scanner.Mode()].
+// Modes without a ScanFunc scan tokens of kind -1.
+func Modal(scans ...ScanFunc) ScanFunc {
+	return func(s *Scanner) int {
+		if mode := s.Mode(); mode >= 0 && mode < len(scans) && scans[mode] != nil {
+			return scans[mode](s)
+		} else if s.Done() {
+			return 0
+		}
+		s.Advance()
+		return -1
+	}
+}
+
+// Modes returns the ModeStack of the Iterator and reports whether
+// a mode change takes effect from the token that follows the one returned by Peek.
+// It lets ParseFuncs tell the Scanner in which context it scans.
+//
+// The ModeStack is found through Lookahead, Semicolons, Offside,
+// Filter, Map and Tee. Modes reports false if none of the Iterators
+// implements ModeStack, or while any of them holds tokens
+// that have already been scanned, for example after PeekN or Rewind.
+func (p *Parser) Modes() (ModeStack, bool) {
+	return iterModes(p.iter)
+}
+
+// modeForwarder is implemented by Iterators that read from another Iterator.
+type modeForwarder interface {
+	// modes returns the ModeStack of the Iterator it reads from
+	// and reports false if it holds tokens that have already been scanned.
+	modes() (ModeStack, bool)
+}
+
+// iterModes returns the ModeStack of it or of the Iterators it reads from.
+func iterModes(it Iterator) (ModeStack, bool) {
+	switch it := it.(type) {
+	case ModeStack:
+		return it, true
+	case modeForwarder:
+		return it.modes()
+	}
+	return nil, false
+}
@@ -0,0 +1,207 @@
+package prattle
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestModes(t *testing.T) {
+	const (
+		quote int = 1 + iota
+		text
+		interpStart
+		interpEnd
+		ident
+		plus
+	)
+
+	const (
+		codeMode int = iota
+		stringMode
+		interpMode
+	)
+
+	code := func(s *Scanner) int {
+		s.ExpectAny(unicode.IsSpace)
+		s.Skip()
+		switch {
+		case s.Done():
+			return 0
+		case s.Expect('"'):
+			s.PushMode(stringMode)
+			return quote
+		case s.Mode() == interpMode && s.Expect('}'):
+			s.PopMode()
+			return interpEnd
+		case s.Expect('+'):
+			return plus
+		case s.ExpectOne(unicode.IsLetter):
+			s.ExpectAny(unicode.IsLetter)
+			return ident
+		}
+		s.Advance()
+		return -1
+	}
+
+	str := func(s *Scanner) int {
+		switch {
+		case s.Done():
+			return 0
+		case s.Expect('"'):
+			s.PopMode()
+			return quote
+		case s.ExpectString("${"):
+			s.PushMode(interpMode)
+			return interpStart
+		}
+		for !s.Done() && s.Peek() != '"' && !s.PeekString("${") {
+			s.Advance()
+		}
+		return text
+	}
+
+	s := Scanner{Scan: Modal(code, str, code)}
+	s.InitWithString(`x + "a ${b + "c${d}"} e" y`)
+
+	var texts []string
+	var kinds []int
+	for tok, ok := s.Next(); ok; tok, ok = s.Next() {
+		texts = append(texts, tok.Text)
+		kinds = append(kinds, tok.Kind)
+	}
+
+	if got, want := strings.Join(texts, "|"), `x|+|"|a |${|b|+|"|c|${|d|}|"|}| e|"|y`; got != want {
+		t.Error(got)
+	}
+
+	want := []int{ident, plus, quote, text, interpStart, ident, plus, quote, text, interpStart, ident, interpEnd, quote, interpEnd, text, quote, ident}
+	for i := range want {
+		if i >= len(kinds) || kinds[i] != want[i] {
+			t.Fatal(kinds)
+		}
+	}
+
+	if s.Mode() != codeMode || s.PopMode() != 0 {
+		t.Error(s.Mode())
+	}
+
+	// Init resets the mode stack
+	s.PushMode(7)
+	if tok, _ := s.InitWithString("x").Next(); tok.Kind != ident {
+		t.Error(tok)
+	}
+
+	// modes without a ScanFunc
+	s.InitWithString("x").PushMode(7)
+	if tok, ok := s.Next(); tok.Kind != -1 || ok {
+		t.Error(tok)
+	} else if tok, _ := s.Next(); tok.Kind != 0 {
+		t.Error(tok)
+	}
+
+	t.Run("Trivia", func(t *testing.T) {
+		trivia := &Trivia{Space: unicode.IsSpace}
+
+		for _, testCase := range []struct {
+			Name    string
+			Scanner Scanner
+			Source  string
+			Expect  string
+		}{
+			{
+				Name:    "Trivia",
+				Scanner: Scanner{Scan: Modal(code, str, code), Trivia: trivia},
+				Source:  `x "  a b"`,
+				Expect:  `x|"|  a b|"`,
+			},
+			{
+				Name:    "Lossless",
+				Scanner: Scanner{Scan: Modal(code, str, code), Trivia: trivia, Lossless: true},
+				Source:  `x "  a b" `,
+				Expect:  `x|"|  a b|"`,
+			},
+			{
+				Name:    "ModeTrivia",
+				Scanner: Scanner{Scan: Modal(code, str, code), ModeTrivia: []*Trivia{trivia, nil, trivia}},
+				Source:  `" ${ b } "`,
+				Expect:  `"| |${|b|}| |"`,
+			},
+		} {
+			t.Run(testCase.Name, func(t *testing.T) {
+				s := testCase.Scanner
+				s.InitWithString(testCase.Source)
+
+				var texts []string
+				for tok, ok := s.Next(); ok; tok, ok = s.Next() {
+					texts = append(texts, tok.Text)
+				}
+
+				if got := strings.Join(texts, "|"); got != testCase.Expect {
+					t.Error(got)
+				}
+			})
+		}
+	})
+}
+
+func TestParserModes(t *testing.T) {
+	s := Scanner{Scan: scanLetters, Trivia: &Trivia{Space: OneOf(" \n")}}
+	p := Parser{Driver: &testDriver{}}
+
+	if modes, ok := p.Init(s.InitWithString("")).Modes(); !ok || modes != &s {
+		t.Error(modes, ok)
+	}
+
+	if modes, ok := p.Init(SliceIterator(nil)).Modes(); ok || modes != nil {
+		t.Error(modes, ok)
+	}
+
+	// tokens buffered by PeekN were scanned in the old mode
+	p.Init(s.InitWithString("a b c d"))
+	p.PeekN(3)
+	for i := 0; i < 3; i++ {
+		if _, ok := p.Modes(); ok {
+			t.Error("expected false while tokens are buffered", i)
+		}
+		p.Advance()
+	}
+	if modes, ok := p.Modes(); !ok || modes != &s {
+		t.Error("expected ModeStack after buffered tokens are consumed")
+	}
+
+	t.Run("Wrapped", func(t *testing.T) {
+		semicolons := Semicolons{Semicolon: 9, Rule: EndKinds(1)}
+		offside := Offside{Newline: 7, Indent: 8}
+		keep := func(Token) bool { return true }
+
+		for _, iter := range []Iterator{
+			semicolons.Init(&s),
+			offside.Init(&s),
+			Filter(&s, keep),
+			Map(&s, func(t Token) Token { return t }),
+			Tee(&s, func(Token) {}),
+		} {
+			s.InitWithString("a\nb")
+			if modes, ok := p.Init(iter).Modes(); !ok || modes != &s {
+				t.Errorf("%T: %v %v", iter, modes, ok)
+			}
+		}
+
+		// Semicolons holds b while it yields the inserted terminator
+		p.Init(semicolons.Init(s.InitWithString("a\nb")))
+		if p.Advance(); p.Peek().Kind != 9 {
+			t.Fatal(p.Peek())
+		} else if _, ok := p.Modes(); ok {
+			t.Error("expected false while Semicolons holds a token")
+		}
+
+		// Offside holds b behind the inserted Newline
+		p.Init(offside.Init(s.InitWithString("a\nb")))
+		if p.Advance(); p.Peek().Kind != 7 {
+			t.Fatal(p.Peek())
+		} else if _, ok := p.Modes(); ok {
+			t.Error("expected false while Offside holds a token")
+		}
+	})
+}
@@ -0,0 +1,402 @@
+package prattle
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// NumberDialect selects the syntax of the numeric literals
+// recognized by Scanner.ScanNumber.
+type NumberDialect int
+
+const (
+	// GoNumbers recognizes Go integer and floating-point literals,
+	// including binary, octal and hexadecimal integers, hexadecimal floats
+	// and '_' digit separators. Imaginary literals are not recognized.
+	GoNumbers NumberDialect = iota
+
+	// CNumbers recognizes C integer and floating-point literals,
+	// including binary, octal and hexadecimal integers, hexadecimal floats,
+	// '\'' digit separators and the integer (u, l, ll) and floating-point (f, l) suffixes.
+	CNumbers
+
+	// JSONNumbers recognizes JSON numbers, including the leading minus sign.
+	JSONNumbers
+
+	// DecimalNumbers recognizes decimal integer and floating-point literals
+	// without base prefixes or digit separators.
+	// Leading zeros are allowed and do not denote an octal literal.
+	DecimalNumbers
+)
+
+// NumberKind identifies the kind of a numeric literal.
+type NumberKind int
+
+const (
+	// NotNumber indicates that the input does not begin with a numeric literal.
+	NotNumber NumberKind = iota
+
+	// DecimalInt is a decimal integer literal such as 1337.
+	DecimalInt
+
+	// BinaryInt is a binary integer literal such as 0b101.
+	BinaryInt
+
+	// OctalInt is an octal integer literal such as 0o755 or 0755.
+	OctalInt
+
+	// HexInt is a hexadecimal integer literal such as 0xff.
+	HexInt
+
+	// DecimalFloat is a decimal floating-point literal such as 1.5e3.
+	DecimalFloat
+
+	// HexFloat is a hexadecimal floating-point literal such as 0x1p-2.
+	HexFloat
+)
+
+// ScanNumber advances the cursor over a numeric literal and returns its kind.
+// It returns NotNumber without advancing the cursor
+// if the input does not begin with a numeric literal.
+//
+// A malformed literal is scanned as far as it extends
+// and reported by an *Error that points at the offending rune.
+func (s *Scanner) ScanNumber(d NumberDialect) (NumberKind, error) {
+	if d == JSONNumbers {
+		return s.scanJSONNumber()
+	}
+
+	if !isDecimal(s.peek) && (s.peek != '.' || !isDecimal(s.PeekN(1))) {
+		return NotNumber, nil
+	}
+
+	n := numberScanner{s: s, sep: '_'}
+	switch d {
+	case CNumbers:
+		n.sep = '\''
+	case DecimalNumbers:
+		n.sep = -1
+	}
+
+	kind, base, prefix := DecimalInt, 10, rune(0)
+
+	// integer part
+	if s.peek != '.' {
+		if d != DecimalNumbers && s.Expect('0') {
+			switch lower(s.peek) {
+			case 'x':
+				kind, base, prefix = HexInt, 16, 'x'
+			case 'b':
+				kind, base, prefix = BinaryInt, 2, 'b'
+			case 'o':
+				if d == GoNumbers {
+					kind, base, prefix = OctalInt, 8, 'o'
+				}
+			}
+
+			if prefix != 0 {
+				s.Advance()
+				// Go allows a separator after the base prefix
+				n.digit = d == GoNumbers
+			} else {
+				// the leading 0 is a digit of a legacy octal literal
+				kind, base, prefix = OctalInt, 8, '0'
+				n.digit, n.digits = true, 1
+			}
+		}
+		n.scanDigits(base)
+	}
+
+	// fractional part
+	if s.peek == '.' {
+		if prefix == 'o' || prefix == 'b' {
+			n.fail(s.Cursor(), "invalid radix point in %s literal", litname(prefix))
+		}
+		s.Advance()
+		if prefix == '0' {
+			base = 10
+		}
+		kind = DecimalFloat
+		n.digit = false
+		n.scanDigits(base)
+	}
+
+	if n.digits == 0 {
+		n.fail(s.Cursor(), "%s literal has no digits", litname(prefix))
+	}
+
+	// exponent
+	if e := lower(s.peek); (e == 'e' && (prefix == 0 || prefix == '0')) || (e == 'p' && prefix == 'x') {
+		s.Advance()
+		kind = DecimalFloat
+		if s.peek == '+' || s.peek == '-' {
+			s.Advance()
+		}
+		n.digit, n.digits = false, 0
+		n.scanDigits(10)
+		if n.digits == 0 {
+			n.fail(s.Cursor(), "exponent has no digits")
+		}
+	} else if prefix == 'x' && kind == DecimalFloat {
+		n.fail(s.Cursor(), "hexadecimal mantissa requires a 'p' exponent")
+	}
+
+	if kind == DecimalFloat {
+		if prefix == 'x' {
+			kind = HexFloat
+		}
+	} else if n.invalid.IsValid() {
+		n.fail(n.invalid, "invalid digit %q in %s literal", n.invalidRune, litname(prefix))
+	} else if prefix == '0' && n.digits == 1 {
+		kind = DecimalInt
+	}
+
+	if d == CNumbers {
+		scanCSuffix(s, kind)
+	}
+
+	return kind, n.err
+}
+
+// scanCSuffix advances the cursor over the suffix of a C literal.
+func scanCSuffix(s *Scanner, kind NumberKind) {
+	if kind == DecimalFloat || kind == HexFloat {
+		s.ExpectOne(OneOf("fFlL"))
+		return
+	}
+
+	for unsigned, long := false, false; ; {
+		if !unsigned && s.ExpectOne(OneOf("uU")) {
+			unsigned = true
+		} else if l := s.Peek(); !long && (l == 'l' || l == 'L') {
+			s.Advance()
+			s.Expect(l)
+			long = true
+		} else {
+			return
+		}
+	}
+}
+
+func (s *Scanner) scanJSONNumber() (NumberKind, error) {
+	if !isDecimal(s.peek) && (s.peek != '-' || !isDecimal(s.PeekN(1))) {
+		return NotNumber, nil
+	}
+
+	n := numberScanner{s: s, sep: -1}
+	kind := DecimalInt
+
+	s.Expect('-')
+	if s.Expect('0') {
+		if isDecimal(s.peek) {
+			n.fail(s.Cursor(), "number has a leading zero")
+			n.scanDigits(10)
+		}
+	} else {
+		n.scanDigits(10)
+	}
+
+	if s.Expect('.') {
+		kind = DecimalFloat
+		n.digits = 0
+		n.scanDigits(10)
+		if n.digits == 0 {
+			n.fail(s.Cursor(), "fraction has no digits")
+		}
+	}
+
+	if s.ExpectOne(OneOf("eE")) {
+		kind = DecimalFloat
+		s.ExpectOne(OneOf("+-"))
+		n.digits = 0
+		n.scanDigits(10)
+		if n.digits == 0 {
+			n.fail(s.Cursor(), "exponent has no digits")
+		}
+	}
+
+	return kind, n.err
+}
+
+// numberScanner holds the state of scanning a numeric literal.
+type numberScanner struct {
+	s           *Scanner
+	sep         rune
+	digit       bool // the previous rune was a digit or separators are allowed
+	digits      int
+	invalid     Position
+	invalidRune rune
+	err         error
+}
+
+// fail records the first error.
+func (n *numberScanner) fail(pos Position, format string, args ...interface{}) {
+	if n.err == nil {
+		n.err = &Error{Position: pos, Err: fmt.Errorf(format, args...)}
+	}
+}
+
+// scanDigits advances the cursor over digits and separators.
+// Decimal digits that are invalid in base are recorded
+// to be reported if the literal turns out not to be a float.
+func (n *numberScanner) scanDigits(base int) {
+	s := n.s
+	var sep Position
+	for {
+		if r := s.peek; r == n.sep {
+			sep = s.Cursor()
+			if !n.digit {
+				n.fail(sep, "%q must separate successive digits", n.sep)
+			}
+			n.digit = false
+		} else if isDigit(r, base) {
+			if d := digitVal(r); d >= base && !n.invalid.IsValid() {
+				n.invalid, n.invalidRune = s.Cursor(), r
+			}
+			n.digit = true
+			n.digits++
+		} else {
+			break
+		}
+		s.Advance()
+	}
+
+	if sep.IsValid() && !n.digit {
+		n.fail(sep, "%q must separate successive digits", n.sep)
+	}
+}
+
+func isDecimal(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func isDigit(r rune, base int) bool {
+	if base <= 10 {
+		return isDecimal(r)
+	}
+	return isDecimal(r) || 'a' <= lower(r) && lower(r) <= 'f'
+}
+
+func digitVal(r rune) int {
+	if isDecimal(r) {
+		return int(r - '0')
+	}
+	return int(lower(r)-'a') + 10
+}
+
+func lower(r rune) rune {
+	return ('a' - 'A') | r
+}
+
+func litname(prefix rune) string {
+	switch prefix {
+	case 'x':
+		return "hexadecimal"
+	case 'o', '0':
+		return "octal"
+	case 'b':
+		return "binary"
+	}
+	return "decimal"
+}
+
+// ErrNotNumber is returned when decoding text that is not a numeric literal.
+var ErrNotNumber error = errors.New("not a numeric literal")
+
+// isFloat reports whether a scanned literal is a floating-point literal.
+func (d NumberDialect) isFloat(text string) bool {
+	if len(text) > 1 && text[0] == '0' && lower(rune(text[1])) == 'x' {
+		return strings.ContainsAny(text, "pP")
+	}
+	return strings.ContainsAny(text, ".eE")
+}
+
+// clean removes the digit separators and suffixes from a literal
+// so that it can be decoded by the strconv and math/big packages.
+func (d NumberDialect) clean(text string) (string, bool) {
+	float := d.isFloat(text)
+
+	switch d {
+	case GoNumbers:
+		text = strings.ReplaceAll(text, "_", "")
+	case CNumbers:
+		text = strings.ReplaceAll(text, "'", "")
+		if float {
+			text = strings.TrimRight(text, "fFlL")
+		} else {
+			text = strings.TrimRight(text, "uUlL")
+		}
+	}
+
+	return text, float
+}
+
+// base returns the base to decode integer literals with,
+// where zero means that the base is implied by the prefix.
+func (d NumberDialect) base() int {
+	if d == DecimalNumbers {
+		return 10
+	}
+	return 0
+}
+
+// ParseInt decodes a numeric literal scanned in dialect d into an int64.
+// Floating-point literals are rejected.
+func (d NumberDialect) ParseInt(text string) (int64, error) {
+	clean, float := d.clean(text)
+	if float {
+		return 0, fmt.Errorf("%q: %w", text, ErrNotNumber)
+	}
+	return strconv.ParseInt(clean, d.base(), 64)
+}
+
+// ParseFloat decodes a numeric literal scanned in dialect d into a float64.
+func (d NumberDialect) ParseFloat(text string) (float64, error) {
+	clean, float := d.clean(text)
+	if float {
+		return strconv.ParseFloat(clean, 64)
+	}
+
+	i, err := d.ParseBigInt(text)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := new(big.Float).SetInt(i).Float64()
+	return f, nil
+}
+
+// ParseBigInt decodes a numeric literal scanned in dialect d into a big.Int.
+// Floating-point literals are rejected.
+func (d NumberDialect) ParseBigInt(text string) (*big.Int, error) {
+	clean, float := d.clean(text)
+	if float {
+		return nil, fmt.Errorf("%q: %w", text, ErrNotNumber)
+	}
+
+	i, ok := new(big.Int).SetString(clean, d.base())
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", text, ErrNotNumber)
+	}
+	return i, nil
+}
+
+// ParseBigFloat decodes a numeric literal scanned in dialect d
+// into a big.Float of precision prec.
+// If prec is zero, the precision is 64 bits or,
+// for integer literals, as large as needed to represent the value exactly.
+func (d NumberDialect) ParseBigFloat(text string, prec uint) (*big.Float, error) {
+	clean, float := d.clean(text)
+	if float {
+		f, _, err := big.ParseFloat(clean, 0, prec, big.ToNearestEven)
+		return f, err
+	}
+
+	i, err := d.ParseBigInt(text)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Float).SetPrec(prec).SetInt(i), nil
+}
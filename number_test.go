@@ -0,0 +1,138 @@
+package prattle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScanNumber(t *testing.T) {
+	for _, testCase := range []struct {
+		Dialect NumberDialect
+		Input   string
+		Text    string
+		Kind    NumberKind
+		Column  int // column of the error, if any
+	}{
+		{GoNumbers, "x", "", NotNumber, 0},
+		{GoNumbers, ".x", "", NotNumber, 0},
+		{GoNumbers, "0", "0", DecimalInt, 0},
+		{GoNumbers, "1_337+", "1_337", DecimalInt, 0},
+		{GoNumbers, "0b1010", "0b1010", BinaryInt, 0},
+		{GoNumbers, "0o755", "0o755", OctalInt, 0},
+		{GoNumbers, "0755", "0755", OctalInt, 0},
+		{GoNumbers, "0x_Ff", "0x_Ff", HexInt, 0},
+		{GoNumbers, "1.", "1.", DecimalFloat, 0},
+		{GoNumbers, ".5", ".5", DecimalFloat, 0},
+		{GoNumbers, "1e5", "1e5", DecimalFloat, 0},
+		{GoNumbers, "1.5E-3", "1.5E-3", DecimalFloat, 0},
+		{GoNumbers, "089.5", "089.5", DecimalFloat, 0},
+		{GoNumbers, "0x1.8p-2", "0x1.8p-2", HexFloat, 0},
+		{GoNumbers, "089", "089", OctalInt, 2},
+		{GoNumbers, "0b102", "0b102", BinaryInt, 5},
+		{GoNumbers, "0x", "0x", HexInt, 3},
+		{GoNumbers, "1__2", "1__2", DecimalInt, 3},
+		{GoNumbers, "12_", "12_", DecimalInt, 3},
+		{GoNumbers, "1e+", "1e+", DecimalFloat, 4},
+		{GoNumbers, "0x1.8", "0x1.8", HexFloat, 6},
+		{GoNumbers, "0b1.0", "0b1.0", DecimalFloat, 4},
+		{DecimalNumbers, "010+", "010", DecimalInt, 0},
+		{DecimalNumbers, "08.5e1", "08.5e1", DecimalFloat, 0},
+		{DecimalNumbers, "0x1", "0", DecimalInt, 0},
+		{DecimalNumbers, "1_0", "1", DecimalInt, 0},
+		{DecimalNumbers, "1e", "1e", DecimalFloat, 3},
+		{CNumbers, "1'000'000ull", "1'000'000ull", DecimalInt, 0},
+		{CNumbers, "0xFFuL", "0xFFuL", HexInt, 0},
+		{CNumbers, "1.5f", "1.5f", DecimalFloat, 0},
+		{CNumbers, "0x1p3L", "0x1p3L", HexFloat, 0},
+		{CNumbers, "0o7", "0", DecimalInt, 0},
+		{CNumbers, "0x'1", "0x'1", HexInt, 3},
+		{JSONNumbers, "-", "", NotNumber, 0},
+		{JSONNumbers, ".5", "", NotNumber, 0},
+		{JSONNumbers, "-0", "-0", DecimalInt, 0},
+		{JSONNumbers, "-12.5e+3", "-12.5e+3", DecimalFloat, 0},
+		{JSONNumbers, "0x1", "0", DecimalInt, 0},
+		{JSONNumbers, "012", "012", DecimalInt, 2},
+		{JSONNumbers, "1.e5", "1.e5", DecimalFloat, 3},
+		{JSONNumbers, "1E", "1E", DecimalFloat, 3},
+	} {
+		var s Scanner
+		s.InitWithString(testCase.Input)
+		kind, err := s.ScanNumber(testCase.Dialect)
+
+		var e *Error
+		if kind != testCase.Kind || s.Text() != testCase.Text {
+			t.Errorf("%q: got %d %q", testCase.Input, kind, s.Text())
+		} else if testCase.Column == 0 && err != nil {
+			t.Errorf("%q: unexpected error %s", testCase.Input, err)
+		} else if testCase.Column != 0 && (!errors.As(err, &e) || e.Column != testCase.Column) {
+			t.Errorf("%q: expected error at column %d, got %v", testCase.Input, testCase.Column, err)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	for _, testCase := range []struct {
+		Dialect NumberDialect
+		Text    string
+		Int     int64
+		Float   float64
+	}{
+		{GoNumbers, "1_337", 1337, 1337},
+		{GoNumbers, "0755", 0755, 0755},
+		{GoNumbers, "0o755", 0755, 0755},
+		{GoNumbers, "0b_1010", 10, 10},
+		{GoNumbers, "0xff", 255, 255},
+		{GoNumbers, "089.5", 0, 89.5},
+		{GoNumbers, "0x1.8p-2", 0, 0.375},
+		{CNumbers, "1'000ull", 1000, 1000},
+		{CNumbers, "0xEf", 239, 239},
+		{CNumbers, "017L", 15, 15},
+		{CNumbers, "2.5e1f", 0, 25},
+		{DecimalNumbers, "010", 10, 10},
+		{DecimalNumbers, "08", 8, 8},
+		{DecimalNumbers, "08.5e1", 0, 85},
+		{JSONNumbers, "-12", -12, -12},
+		{JSONNumbers, "-1.5e2", 0, -150},
+	} {
+		isFloat := testCase.Dialect.isFloat(testCase.Text)
+
+		i, err := testCase.Dialect.ParseInt(testCase.Text)
+		if isFloat && err == nil || !isFloat && (err != nil || i != testCase.Int) {
+			t.Errorf("%q: ParseInt %d %v", testCase.Text, i, err)
+		}
+
+		if f, err := testCase.Dialect.ParseFloat(testCase.Text); err != nil || f != testCase.Float {
+			t.Errorf("%q: ParseFloat %f %v", testCase.Text, f, err)
+		}
+
+		bi, err := testCase.Dialect.ParseBigInt(testCase.Text)
+		if isFloat && err == nil || !isFloat && (err != nil || bi.Int64() != testCase.Int) {
+			t.Errorf("%q: ParseBigInt %v %v", testCase.Text, bi, err)
+		}
+
+		if bf, err := testCase.Dialect.ParseBigFloat(testCase.Text, 0); err != nil {
+			t.Errorf("%q: ParseBigFloat %v", testCase.Text, err)
+		} else if f, _ := bf.Float64(); f != testCase.Float {
+			t.Errorf("%q: ParseBigFloat %v", testCase.Text, bf)
+		}
+	}
+
+	huge := "123456789012345678901234567890"
+	if _, err := GoNumbers.ParseInt(huge); err == nil {
+		t.Error("expected overflow")
+	} else if bi, _ := GoNumbers.ParseBigInt(huge); bi.String() != huge {
+		t.Error(bi)
+	} else if bf, _ := GoNumbers.ParseBigFloat(huge, 0); bf.Text('f', 0) != huge {
+		t.Error(bf)
+	} else if _, err := GoNumbers.ParseBigInt("1.5"); !errors.Is(err, ErrNotNumber) {
+		t.Error(err)
+	} else if _, err := GoNumbers.ParseBigInt("0x"); !errors.Is(err, ErrNotNumber) {
+		t.Error(err)
+	}
+
+	if _, err := GoNumbers.ParseBigFloat("1.5", 100); err != nil {
+		t.Error(err)
+	} else if f, _ := GoNumbers.ParseBigFloat("1", 200); f.Prec() != 200 {
+		t.Error(f.Prec())
+	}
+}
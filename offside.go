@@ -0,0 +1,197 @@
+package prattle
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInconsistentIndent is reported by Offside.Err
+// when a line is indented inconsistently with the enclosing lines,
+// either because it mixes tabs and spaces differently
+// or because it does not dedent to an enclosing indentation level.
+var ErrInconsistentIndent error = errors.New("inconsistent indentation")
+
+// ErrUnknownIndent is reported by Offside.Err
+// when the first token of a line has neither Leading text nor a Column.
+var ErrUnknownIndent error = errors.New("unknown indentation")
+
+// Offside is an Iterator that implements the off-side rule
+// of indentation-sensitive languages.
+// It reads tokens from another Iterator and inserts a Newline token
+// at the end of every logical line, an Indent token
+// before the first token of a line that is indented deeper than the line before,
+// and a Dedent token for every indentation level that such a token closes.
+// The synthetic tokens have no text and are positioned
+// at the end of the line and at the first token of the next line, respectively.
+//
+// The indentation of a line is taken from the Leading text
+// of its first token if a Scanner in lossless mode has set it,
+// which distinguishes tabs from spaces.
+// Otherwise it is taken from the Column of the first token,
+// so tabs count as many columns as the Scanner counts them.
+// Lines inside brackets continue the logical line.
+type Offside struct {
+	// Newline, Indent and Dedent are the kinds of the synthetic tokens.
+	Newline, Indent, Dedent int
+
+	// Open and Close are the kinds of the opening and closing brackets.
+	Open, Close []int
+
+	iter   Iterator
+	stack  []string
+	queue  []Token
+	last   Token
+	depth  int
+	inLine bool
+	err    error
+}
+
+// Init initializes Offside with an Iterator and returns it.
+func (o *Offside) Init(iter Iterator) *Offside {
+	o.iter = iter
+	o.stack = append(o.stack[:0], "")
+	o.queue = o.queue[:0]
+	o.last = Token{}
+	o.depth = 0
+	o.inLine = false
+	o.err = nil
+	return o
+}
+
+// Next implements Iterator.
+func (o *Offside) Next() (Token, bool) {
+	if len(o.queue) == 0 && o.err == nil {
+		o.read()
+	}
+
+	if len(o.queue) == 0 {
+		return Token{Position: o.last.End, End: o.last.End}, false
+	}
+
+	tok := o.queue[0]
+	o.queue = o.queue[1:]
+	return tok, tok.Kind > 0
+}
+
+// read reads the next token and queues it after the synthetic tokens before it.
+func (o *Offside) read() {
+	tok, ok := o.iter.Next()
+
+	if atEnd(tok, ok) {
+		if o.err = iterErr(o.iter); o.err != nil {
+			return
+		}
+		if o.inLine {
+			o.push(o.Newline, o.last.End)
+		}
+		for i := 1; i < len(o.stack); i++ {
+			o.push(o.Dedent, tok.Position)
+		}
+		o.stack = o.stack[:1]
+		o.inLine = false
+		o.queue = append(o.queue, tok)
+		return
+	}
+
+	if o.depth == 0 && (!o.inLine || tok.Line > o.last.End.Line) {
+		if o.inLine {
+			o.push(o.Newline, o.last.End)
+		}
+		if !o.indent(tok) {
+			return
+		}
+	}
+
+	if containsKind(o.Open, tok.Kind) {
+		o.depth++
+	} else if containsKind(o.Close, tok.Kind) && o.depth > 0 {
+		o.depth--
+	}
+
+	o.queue = append(o.queue, tok)
+	o.last = tok
+	o.inLine = true
+}
+
+// indent compares the indentation of tok with the enclosing levels
+// and queues Indent or Dedent tokens.
+func (o *Offside) indent(tok Token) bool {
+	indent, ok := lineIndent(tok)
+	if !ok {
+		o.queue = o.queue[:0]
+		o.err = &Error{Position: tok.Position, Err: ErrUnknownIndent}
+		return false
+	}
+	top := o.stack[len(o.stack)-1]
+
+	switch {
+	case indent == top:
+		return true
+	case strings.HasPrefix(indent, top):
+		o.stack = append(o.stack, indent)
+		o.push(o.Indent, tok.Position)
+		return true
+	case strings.HasPrefix(top, indent):
+		for n := len(o.stack) - 1; n > 0 && strings.HasPrefix(o.stack[n], indent); n-- {
+			if o.stack[n-1] == indent {
+				for i := len(o.stack) - 1; i >= n; i-- {
+					o.push(o.Dedent, tok.Position)
+				}
+				o.stack = o.stack[:n]
+				return true
+			}
+		}
+	}
+
+	o.queue = o.queue[:0]
+	o.err = &Error{Position: tok.Position, Err: ErrInconsistentIndent}
+	return false
+}
+
+func (o *Offside) modes() (ModeStack, bool) {
+	if len(o.queue) > 0 {
+		return nil, false
+	}
+	return iterModes(o.iter)
+}
+
+func (o *Offside) push(kind int, pos Position) {
+	o.queue = append(o.queue, Token{Position: pos, End: pos, Kind: kind})
+}
+
+// Err returns the error that ended the token stream, if any.
+// It is either an *Error that wraps ErrInconsistentIndent or ErrUnknownIndent,
+// or the error reported by the Err method of the underlying Iterator.
+func (o *Offside) Err() error {
+	return o.err
+}
+
+// lineIndent returns the indentation of the line that tok begins.
+// Without Leading text, it is represented by a space per column.
+func lineIndent(tok Token) (string, bool) {
+	if tok.Leading != "" {
+		return indentation(tok.Leading), true
+	} else if tok.Column > 0 {
+		return strings.Repeat(" ", tok.Column-1), true
+	}
+	return "", false
+}
+
+// indentation returns the spaces and tabs that begin the last line of leading.
+func indentation(leading string) string {
+	if i := strings.LastIndexAny(leading, "\n\r\u0085\u2028\u2029"); i >= 0 {
+		_, size := utf8.DecodeRuneInString(leading[i:])
+		leading = leading[i+size:]
+	}
+	return leading[:len(leading)-len(strings.TrimLeft(leading, " \t"))]
+}
+
+func containsKind(kinds []int, kind int) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package prattle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestOffside(t *testing.T) {
+	const (
+		word int = 1 + iota
+		open
+		close
+		newline
+		indent
+		dedent
+	)
+
+	scan := func(s *Scanner) int {
+		switch {
+		case s.Done():
+			return 0
+		case s.Expect('('):
+			return open
+		case s.Expect(')'):
+			return close
+		}
+		return scanLetters(s)
+	}
+
+	scanner := Scanner{
+		Scan:     scan,
+		Lossless: true,
+		Trivia:   &Trivia{Space: unicode.IsSpace, LineComments: []string{"#"}},
+	}
+
+	offside := Offside{
+		Newline: newline,
+		Indent:  indent,
+		Dedent:  dedent,
+		Open:    []int{open},
+		Close:   []int{close},
+	}
+
+	collect := func(source string) (string, []Token, error) {
+		offside.Init(scanner.InitWithString(source))
+		var texts []string
+		var toks []Token
+		for tok, ok := offside.Next(); ok; tok, ok = offside.Next() {
+			toks = append(toks, tok)
+			switch tok.Kind {
+			case newline:
+				texts = append(texts, ";")
+			case indent:
+				texts = append(texts, "{")
+			case dedent:
+				texts = append(texts, "}")
+			default:
+				texts = append(texts, tok.Text)
+			}
+		}
+		return strings.Join(texts, " "), toks, offside.Err()
+	}
+
+	t.Run("Blocks", func(t *testing.T) {
+		source := "a\n  b\n\n    c # x\n  # y\n      # z\n  d (\ne\n  f) g\nh\n\tk\n"
+		texts, toks, err := collect(source)
+		if err != nil {
+			t.Fatal(err)
+		} else if want := "a ; { b ; { c ; } d ( e f ) g ; } h ; { k ; }"; texts != want {
+			t.Fatal(texts)
+		}
+
+		if pos := toks[1].Position; toks[1].Kind != newline || pos.Line != 1 || pos.Column != 2 {
+			t.Error(toks[1])
+		} else if pos := toks[2].Position; toks[2].Kind != indent || pos.Line != 2 || pos.Column != 3 {
+			t.Error(toks[2])
+		} else if pos := toks[8].Position; toks[8].Kind != dedent || pos.Line != 7 || pos.Column != 3 {
+			t.Error(toks[8])
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if texts, _, err := collect("\n  # x\n"); err != nil || texts != "" {
+			t.Error(texts, err)
+		}
+	})
+
+	t.Run("EndOfInput", func(t *testing.T) {
+		if texts, _, err := collect("a\n b\n  c"); err != nil || texts != "a ; { b ; { c ; } }" {
+			t.Error(texts, err)
+		}
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		scanner.Lossless = false
+		defer func() { scanner.Lossless = true }()
+
+		source := "a\n  b\n\n    c # x\n  d (\ne\n  f) g\nh\n\tk\n"
+		if texts, _, err := collect(source); err != nil || texts != "a ; { b ; { c ; } d ( e f ) g ; } h ; { k ; }" {
+			t.Error(texts, err)
+		}
+
+		offside.Init(SliceIterator([]Token{
+			{Position: Position{Line: 1, Column: 1}, Kind: word},
+			{Position: Position{Line: 2, Column: 3}, Kind: word},
+			{Position: Position{Line: 3}, Kind: word},
+		}))
+		var kinds []int
+		for tok, ok := offside.Next(); ok; tok, ok = offside.Next() {
+			kinds = append(kinds, tok.Kind)
+		}
+		if len(kinds) != 4 || kinds[2] != indent || !errors.Is(offside.Err(), ErrUnknownIndent) {
+			t.Error(kinds, offside.Err())
+		}
+	})
+
+	for _, testCase := range []struct {
+		Name   string
+		Source string
+		Line   int
+	}{
+		{"Mixed", "a\n\t b\n \tc", 3},
+		{"Dedent", "a\n    b\n  c", 3},
+	} {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, _, err := collect(testCase.Source)
+			var e *Error
+			if !errors.Is(err, ErrInconsistentIndent) || !errors.As(err, &e) || e.Line != testCase.Line {
+				t.Error(err)
+			}
+		})
+	}
+}
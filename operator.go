@@ -0,0 +1,89 @@
+package prattle
+
+import "sort"
+
+// OperatorSet is a trie of operator spellings
+// that is used by Scanner.ScanOperator to find the longest matching operator.
+type OperatorSet struct {
+	root operatorNode
+}
+
+type operatorNode struct {
+	kind     int
+	runes    []rune // sorted
+	children []*operatorNode
+}
+
+// NewOperatorSet creates an OperatorSet that maps operator spellings to token kinds.
+// Spellings may be any non-empty string, including keywords and non-ASCII symbols.
+// Empty spellings and spellings that map to kinds less than one are ignored.
+func NewOperatorSet(operators map[string]int) *OperatorSet {
+	var set OperatorSet
+	for op, kind := range operators {
+		if op == "" || kind < 1 {
+			continue
+		}
+		node := &set.root
+		for _, r := range op {
+			node = node.child(r)
+		}
+		node.kind = kind
+	}
+	return &set
+}
+
+// child returns the child node for r, inserting it if it does not exist.
+func (n *operatorNode) child(r rune) *operatorNode {
+	i := sort.Search(len(n.runes), func(i int) bool { return n.runes[i] >= r })
+	if i < len(n.runes) && n.runes[i] == r {
+		return n.children[i]
+	}
+
+	child := &operatorNode{}
+	n.runes = append(n.runes, 0)
+	n.children = append(n.children, nil)
+	copy(n.runes[i+1:], n.runes[i:])
+	copy(n.children[i+1:], n.children[i:])
+	n.runes[i] = r
+	n.children[i] = child
+	return child
+}
+
+// lookup returns the child node for r or nil if it does not exist.
+func (n *operatorNode) lookup(r rune) *operatorNode {
+	lo, hi := 0, len(n.runes)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if n.runes[m] < r {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	if lo < len(n.runes) && n.runes[lo] == r {
+		return n.children[lo]
+	}
+	return nil
+}
+
+// ScanOperator advances the cursor over the longest operator in ops
+// that the input begins with and returns its kind.
+// It returns zero without advancing the cursor if no operator matches.
+func (s *Scanner) ScanOperator(ops *OperatorSet) int {
+	kind, length := 0, 0
+	node := &ops.root
+	for i := 0; ; i++ {
+		if i > 0 && s.peekw == 0 {
+			break
+		} else if node = node.lookup(s.PeekN(i)); node == nil {
+			break
+		} else if node.kind > 0 {
+			kind, length = node.kind, i+1
+		}
+	}
+
+	for ; length > 0; length-- {
+		s.Advance()
+	}
+	return kind
+}
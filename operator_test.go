@@ -0,0 +1,52 @@
+package prattle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOperatorSet(t *testing.T) {
+	ops := NewOperatorSet(map[string]int{
+		"<":   1,
+		"<<":  2,
+		"<<=": 3,
+		"<=":  4,
+		"...": 5,
+		"≠":   6,
+		"√":   7,
+		"√√":  8,
+		"in":  9,
+		"":    10,
+	})
+
+	for _, testCase := range []struct {
+		Input  string
+		Kind   int
+		Length int
+	}{
+		{"<", 1, 1},
+		{"<<", 2, 2},
+		{"<<=x", 3, 3},
+		{"<=<", 4, 2},
+		{"<-", 1, 1},
+		{"..", 0, 0},
+		{"...", 5, 3},
+		{"≠=", 6, 1},
+		{"√√√", 8, 2},
+		{"inx", 9, 2},
+		{"i", 0, 0},
+		{"", 0, 0},
+		{"x", 0, 0},
+	} {
+		for _, s := range []*Scanner{
+			new(Scanner).InitWithString(testCase.Input),
+			new(Scanner).InitWithReader(strings.NewReader(testCase.Input)),
+		} {
+			if kind := s.ScanOperator(ops); kind != testCase.Kind {
+				t.Errorf("%q: kind %d", testCase.Input, kind)
+			} else if n := s.Cursor().Column - 1; n != testCase.Length {
+				t.Errorf("%q: length %d", testCase.Input, n)
+			}
+		}
+	}
+}
@@ -45,13 +45,32 @@ type Parser struct {
 	// Driver drives the Parser.
 	Driver
 
-	iter  Iterator
-	token Token
+	// Memoize records the errors of failed calls to Parse
+	// by token position and precedence, so that an alternative
+	// that failed while parsing speculatively fails immediately when retried.
+	// It assumes that the outcome of Parse depends only on the tokens.
+	// Successful calls are not recorded and are parsed again when retried,
+	// because their results live in the state of the Driver.
+	// Init wraps the Iterator in a Lookahead if Memoize is set.
+	Memoize bool
+
+	iter   Iterator
+	token  Token
+	err    error
+	memo   map[memoKey]error
+	marks  []int // ids of the checkpoints that are held
+	markID int   // id of the last checkpoint
 }
 
 // Init initializes the Parser with an Iterator and returns it.
 func (p *Parser) Init(iter Iterator) *Parser {
 	p.iter = iter
+	p.err = nil
+	p.memo = nil
+	p.marks = p.marks[:0]
+	if p.Memoize {
+		p.lookahead()
+	}
 	p.Advance()
 	return p
 }
@@ -63,7 +82,17 @@ func (p *Parser) Peek() Token {
 
 // Advance reads the next token from the Iterator.
 func (p *Parser) Advance() {
-	p.token, _ = p.iter.Next()
+	var ok bool
+	if p.token, ok = p.iter.Next(); !ok && p.err == nil {
+		p.err = iterErr(p.iter)
+	}
+}
+
+// Err returns the error that ended the token stream, if any.
+// The error is reported by the Iterator through an optional Err method,
+// such as Scanner.Err.
+func (p *Parser) Err() error {
+	return p.err
 }
 
 // Expect advances to the next token if the current token kind matches.
@@ -79,7 +108,28 @@ func (p *Parser) Expect(kind int) bool {
 // with an equal or lower precedence than least.
 // It may be called in a mutual recursive manner by the parsing functions
 // provided by the Driver.
+// If the token stream was ended by an error, Parse returns that error
+// instead of treating it as the end of input.
 func (p *Parser) Parse(least int) error {
+	if p.err != nil {
+		return p.err
+	} else if !p.Memoize {
+		return p.parse(least)
+	}
+
+	key, err := p.memoized(least)
+	if err != nil {
+		return err
+	} else if err = p.parse(least); err != nil {
+		if p.memo == nil {
+			p.memo = make(map[memoKey]error)
+		}
+		p.memo[key] = err
+	}
+	return err
+}
+
+func (p *Parser) parse(least int) error {
 	t := p.Peek()
 	p.Advance()
 
@@ -101,5 +151,5 @@ func (p *Parser) Parse(least int) error {
 		}
 	}
 
-	return nil
+	return p.err
 }
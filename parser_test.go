@@ -16,16 +16,6 @@ func (d testDriver) Prefix(int) ParseFunc     { return d.prefix }
 func (testDriver) Precedence(int) int         { return 1 }
 func (d testDriver) ParseError(t Token) error { return fmt.Errorf("kind: %d", t.Kind) }
 
-type tokeniter []Token
-
-func (it *tokeniter) Next() (tok Token, ok bool) {
-	if len(*it) != 0 {
-		tok, *it = (*it)[0], (*it)[1:]
-		return tok, true
-	}
-	return
-}
-
 func requireError(t testing.TB, err error) {
 	if err == nil {
 		t.Helper()
@@ -45,8 +35,7 @@ func TestPrefixErrors(t *testing.T) {
 
 	t.Run("one", func(t *testing.T) {
 		p := Parser{Driver: &testDriver{}}
-		it := tokeniter(tokens)
-		p.Init(&it)
+		p.Init(SliceIterator(tokens))
 		requireError(t, p.Parse(0))
 	})
 
@@ -56,8 +45,7 @@ func TestPrefixErrors(t *testing.T) {
 				prefix: func(p *Parser, t Token) error { return errors.New("") },
 			},
 		}
-		it := tokeniter(tokens)
-		p.Init(&it)
+		p.Init(SliceIterator(tokens))
 		requireError(t, p.Parse(0))
 	})
 }
@@ -69,8 +57,7 @@ func TestInfixErrors(t *testing.T) {
 		p := Parser{Driver: &testDriver{
 			prefix: func(p *Parser, t Token) error { return nil },
 		}}
-		it := tokeniter(tokens)
-		p.Init(&it)
+		p.Init(SliceIterator(tokens))
 		requireError(t, p.Parse(0))
 	})
 
@@ -81,8 +68,7 @@ func TestInfixErrors(t *testing.T) {
 				infix:  func(p *Parser, t Token) error { return errors.New("") },
 			},
 		}
-		it := tokeniter(tokens)
-		p.Init(&it)
+		p.Init(SliceIterator(tokens))
 		requireError(t, p.Parse(0))
 	})
 
@@ -93,8 +79,7 @@ func TestInfixErrors(t *testing.T) {
 				infix:  func(p *Parser, t Token) error { return NonAssoc },
 			},
 		}
-		it := tokeniter(tokens)
-		p.Init(&it)
+		p.Init(SliceIterator(tokens))
 		requireNoError(t, p.Parse(0))
 	})
 }
@@ -102,8 +87,7 @@ func TestInfixErrors(t *testing.T) {
 func TestParserExpect(t *testing.T) {
 	tokens := []Token{{Kind: 1}}
 	p := Parser{}
-	it := tokeniter(tokens)
-	p.Init(&it)
+	p.Init(SliceIterator(tokens))
 	if p.Expect(3) {
 		t.Error("expected false")
 	}
@@ -114,3 +98,28 @@ func TestErrorString(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+type erriter struct {
+	Iterator
+	err error
+}
+
+func (it *erriter) Err() error { return it.err }
+
+func TestParserErr(t *testing.T) {
+	errBroken := errors.New("broken")
+
+	p := Parser{
+		Driver: &testDriver{
+			prefix: func(p *Parser, t Token) error { return nil },
+			infix:  func(p *Parser, t Token) error { return p.Parse(1) },
+		},
+	}
+
+	it := erriter{Iterator: SliceIterator([]Token{{Kind: 1}, {Kind: 2}}), err: errBroken}
+	if err := p.Init(&it).Parse(0); err != errBroken {
+		t.Fatal(err)
+	} else if p.Err() != errBroken {
+		t.Fatal(p.Err())
+	}
+}
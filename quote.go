@@ -0,0 +1,386 @@
+package prattle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrUnterminatedLiteral is reported by Scanner.ScanQuoted
+// when a quoted literal is not terminated.
+var ErrUnterminatedLiteral error = errors.New("unterminated literal")
+
+// QuoteDialect selects the syntax of the quoted literals
+// recognized by Scanner.ScanQuoted.
+type QuoteDialect int
+
+const (
+	// GoQuotes recognizes Go interpreted strings ("..."), rune literals ('...')
+	// and raw strings (`...`), including all Go escape sequences.
+	GoQuotes QuoteDialect = iota
+
+	// JSONQuotes recognizes JSON strings ("..."), including \uXXXX escapes
+	// and surrogate pairs.
+	JSONQuotes
+
+	// CQuotes recognizes C strings ("...") and character constants ('...'),
+	// including octal, hexadecimal and universal character name escapes.
+	CQuotes
+
+	// SQLQuotes recognizes SQL strings ('...') and quoted identifiers ("..."),
+	// in which the quote is escaped by doubling it.
+	SQLQuotes
+
+	// RawQuotes recognizes "...", '...' and `...` without any escapes.
+	RawQuotes
+
+	// HeredocQuotes recognizes here-documents that start with <<TAG
+	// at the end of a line and end with a line that consists of TAG.
+	// The value is the text of the lines in between.
+	HeredocQuotes
+)
+
+func (d QuoteDialect) isQuote(r rune) bool {
+	switch d {
+	case GoQuotes, RawQuotes:
+		return r == '"' || r == '\'' || r == '`'
+	case JSONQuotes:
+		return r == '"'
+	case CQuotes, SQLQuotes:
+		return r == '"' || r == '\''
+	}
+	return false
+}
+
+// ScanQuoted advances the cursor over a quoted literal
+// and returns the opening quote and the decoded value.
+// The opening quote of a here-document is '<'.
+// It returns a zero quote without advancing the cursor
+// if the input does not begin with a quoted literal.
+//
+// An unterminated literal is reported by an *Error that points at the opening quote
+// and wraps ErrUnterminatedLiteral. Other malformed literals are reported
+// by an *Error that points at the offending rune.
+// Interpreted literals end at the end of the line if they are not terminated.
+func (s *Scanner) ScanQuoted(d QuoteDialect) (quote rune, value string, err error) {
+	q := quoteScanner{s: s, start: s.Cursor(), buf: s.quoted[:0]}
+
+	switch quote = s.peek; {
+	case d == HeredocQuotes && s.PeekString("<<") && isTagRune(s.PeekN(2)):
+		quote = '<'
+		q.scanHeredoc()
+	case !d.isQuote(quote):
+		return 0, "", nil
+	case d == RawQuotes || d == GoQuotes && quote == '`':
+		q.scanRaw(quote, d == GoQuotes)
+	case d == SQLQuotes:
+		q.scanDoubled(quote)
+	default:
+		q.scanInterpreted(d, quote)
+	}
+
+	value = string(q.buf)
+	s.quoted = q.buf
+	return quote, value, q.err
+}
+
+// quoteScanner holds the state of scanning a quoted literal.
+type quoteScanner struct {
+	s     *Scanner
+	start Position
+	buf   []byte
+	err   error
+}
+
+// fail records the first error.
+func (q *quoteScanner) fail(pos Position, err error) {
+	if q.err == nil {
+		q.err = &Error{Position: pos, Err: err}
+	}
+}
+
+func (q *quoteScanner) unterminated() {
+	q.fail(q.start, ErrUnterminatedLiteral)
+}
+
+func (q *quoteScanner) appendRune(r rune) {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	q.buf = append(q.buf, b[:n]...)
+}
+
+// scanRaw scans a literal without escapes.
+// Go raw strings discard carriage returns.
+func (q *quoteScanner) scanRaw(quote rune, discardCR bool) {
+	s := q.s
+	s.Advance()
+	for !s.Expect(quote) {
+		if s.Done() {
+			q.unterminated()
+			return
+		} else if r := s.Peek(); r != '\r' || !discardCR {
+			q.appendRune(r)
+		}
+		s.Advance()
+	}
+}
+
+// scanDoubled scans a literal in which the quote is escaped by doubling it.
+func (q *quoteScanner) scanDoubled(quote rune) {
+	s := q.s
+	s.Advance()
+	for {
+		switch {
+		case s.Done():
+			q.unterminated()
+			return
+		case s.Expect(quote):
+			if !s.Expect(quote) {
+				return
+			}
+			q.appendRune(quote)
+		default:
+			q.appendRune(s.Peek())
+			s.Advance()
+		}
+	}
+}
+
+// scanInterpreted scans a literal with backslash escapes.
+func (q *quoteScanner) scanInterpreted(d QuoteDialect, quote rune) {
+	s := q.s
+	s.Advance()
+
+	n := 0
+	for ; !s.Expect(quote); n++ {
+		r := s.Peek()
+		switch {
+		case s.Done() || s.LineEndings.isTerminator(r):
+			q.unterminated()
+			return
+		case r == '\\':
+			q.scanEscape(d, quote)
+		case d == JSONQuotes && r < ' ':
+			q.fail(s.Cursor(), fmt.Errorf("invalid control character %U in string", r))
+			s.Advance()
+		default:
+			q.appendRune(r)
+			s.Advance()
+		}
+	}
+
+	if d == GoQuotes && quote == '\'' && n != 1 {
+		q.fail(q.start, errors.New("rune literal must contain exactly one character"))
+	}
+}
+
+// scanEscape scans and decodes an escape sequence.
+func (q *quoteScanner) scanEscape(d QuoteDialect, quote rune) {
+	s := q.s
+	pos := s.Cursor()
+	s.Advance()
+
+	r := s.Peek()
+	switch r {
+	case 'b', 'f', 'n', 'r', 't', '\\':
+		q.buf = append(q.buf, "\b\f\n\r\t\\"[strings.IndexRune("bfnrt\\", r)])
+		s.Advance()
+		return
+	case 'a', 'v':
+		if d != JSONQuotes {
+			q.buf = append(q.buf, "\a\v"[strings.IndexRune("av", r)])
+			s.Advance()
+			return
+		}
+	case '"', '\'':
+		if r == quote || d == CQuotes {
+			q.appendRune(r)
+			s.Advance()
+			return
+		}
+	case '/':
+		if d == JSONQuotes {
+			q.appendRune(r)
+			s.Advance()
+			return
+		}
+	case '?':
+		if d == CQuotes {
+			q.appendRune(r)
+			s.Advance()
+			return
+		}
+	case 'u', 'U':
+		if r == 'u' || d != JSONQuotes {
+			q.scanUnicodeEscape(d, pos)
+			return
+		}
+	case 'x':
+		if d != JSONQuotes {
+			q.scanByteEscape(d, pos, 16)
+			return
+		}
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		if d != JSONQuotes {
+			q.scanByteEscape(d, pos, 8)
+			return
+		}
+	}
+
+	if s.Done() || s.LineEndings.isTerminator(r) {
+		return
+	}
+	q.fail(pos, errors.New("unknown escape sequence"))
+	s.Advance()
+}
+
+// scanByteEscape scans an octal or hexadecimal escape that encodes one byte.
+// Go requires exactly 3 octal or 2 hexadecimal digits,
+// C accepts up to 3 octal or any number of hexadecimal digits.
+func (q *quoteScanner) scanByteEscape(d QuoteDialect, pos Position, base int) {
+	s := q.s
+	min, max := 3, 3
+	if base == 16 {
+		s.Advance()
+		min, max = 2, 2
+		if d == CQuotes {
+			max = -1
+		}
+	} else if d == CQuotes {
+		min = 1
+	}
+
+	var x, n int
+	for ; n != max && isDigit(s.Peek(), base) && digitVal(s.Peek()) < base; n++ {
+		x = x*base + digitVal(s.Peek())
+		s.Advance()
+	}
+
+	if n < min {
+		q.fail(pos, errors.New("escape sequence has too few digits"))
+	} else if x > 255 {
+		q.fail(pos, errors.New("escape sequence is out of range"))
+	} else {
+		q.buf = append(q.buf, byte(x))
+	}
+}
+
+// scanUnicodeEscape scans a \uXXXX or \UXXXXXXXX escape.
+// JSON combines surrogate pairs and decodes lone surrogates as U+FFFD.
+func (q *quoteScanner) scanUnicodeEscape(d QuoteDialect, pos Position) {
+	s := q.s
+	r, ok := q.scanHex(pos)
+	if !ok {
+		return
+	}
+
+	if d == JSONQuotes && utf16.IsSurrogate(r) {
+		if s.PeekString(`\u`) {
+			m := s.Mark()
+			s.Advance()
+			if r2, ok := q.scanHex(pos); ok && utf16.DecodeRune(r, r2) != utf8.RuneError {
+				r = utf16.DecodeRune(r, r2)
+			} else {
+				s.Reset(m)
+				r = utf8.RuneError
+			}
+		} else {
+			r = utf8.RuneError
+		}
+	} else if !utf8.ValidRune(r) {
+		q.fail(pos, errors.New("escape sequence is invalid Unicode code point"))
+		return
+	}
+
+	q.appendRune(r)
+}
+
+// scanHex scans the hexadecimal digits of a \u or \U escape.
+// The cursor must be at the u or U.
+func (q *quoteScanner) scanHex(pos Position) (rune, bool) {
+	s := q.s
+	n := 4
+	if s.Peek() == 'U' {
+		n = 8
+	}
+	s.Advance()
+
+	var x rune
+	for i := 0; i < n; i++ {
+		if !isDigit(s.Peek(), 16) {
+			q.fail(pos, errors.New("escape sequence has too few digits"))
+			return 0, false
+		}
+		x = x<<4 | rune(digitVal(s.Peek()))
+		s.Advance()
+	}
+	return x, true
+}
+
+// scanHeredoc scans a here-document.
+func (q *quoteScanner) scanHeredoc() {
+	s := q.s
+	s.Advance()
+	s.Advance()
+
+	m := s.Mark()
+	for isTagRune(s.Peek()) {
+		s.Advance()
+	}
+	tag := s.Text()[m.cursor-s.Offset:]
+
+	if tag == "" {
+		q.fail(s.Cursor(), errors.New("here-document has no tag"))
+		return
+	} else if !q.skipLineEnd() {
+		q.fail(s.Cursor(), errors.New("here-document tag must end the line"))
+		return
+	}
+
+	for !s.Done() {
+		// check for the closing tag
+		m := s.Mark()
+		if s.ExpectString(tag) && q.atLineEnd() {
+			if n := len(q.buf); n > 0 && q.buf[n-1] == '\n' {
+				q.buf = q.buf[:n-1]
+			}
+			return
+		}
+		s.Reset(m)
+
+		for !q.atLineEnd() {
+			q.appendRune(s.Peek())
+			s.Advance()
+		}
+		if q.skipLineEnd() {
+			q.buf = append(q.buf, '\n')
+		}
+	}
+
+	q.unterminated()
+}
+
+// atLineEnd reports whether the cursor is at the end of a line.
+func (q *quoteScanner) atLineEnd() bool {
+	s := q.s
+	return s.Done() || s.Peek() == '\r' || s.LineEndings.isTerminator(s.Peek())
+}
+
+// skipLineEnd advances the cursor over a line terminator.
+func (q *quoteScanner) skipLineEnd() bool {
+	s := q.s
+	if s.Expect('\r') {
+		s.Expect('\n')
+		return true
+	} else if s.LineEndings.isTerminator(s.Peek()) {
+		s.Advance()
+		return true
+	}
+	return false
+}
+
+func isTagRune(r rune) bool {
+	return r == '_' || isDecimal(r) || 'a' <= lower(r) && lower(r) <= 'z'
+}
@@ -0,0 +1,79 @@
+package prattle
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScanQuoted(t *testing.T) {
+	for _, testCase := range []struct {
+		Dialect QuoteDialect
+		Input   string
+		Text    string
+		Quote   rune
+		Value   string
+		Column  int // column of the error, if any
+	}{
+		{GoQuotes, "x", "", 0, "", 0},
+		{GoQuotes, `"a\tb\x41\101é\U0001F600\"" x`, `"a\tb\x41\101é\U0001F600\""`, '"', "a\tbAAé😀\"", 0},
+		{GoQuotes, `'\''`, `'\''`, '\'', "'", 0},
+		{GoQuotes, "`a\\n\r\nb`", "`a\\n\r\nb`", '`', "a\\n\nb", 0},
+		{GoQuotes, `"abc`, `"abc`, '"', "abc", 1},
+		{GoQuotes, "x\"ab\ncd\"", "", 0, "", 0},
+		{GoQuotes, `'ab'`, `'ab'`, '\'', "ab", 1},
+		{GoQuotes, `"a\q"`, `"a\q"`, '"', "a", 3},
+		{GoQuotes, `"\'"`, `"\'"`, '"', "", 2},
+		{GoQuotes, `"\400"`, `"\400"`, '"', "", 2},
+		{GoQuotes, `"\x4"`, `"\x4"`, '"', "", 2},
+		{GoQuotes, `"\uD800"`, `"\uD800"`, '"', "", 2},
+		{JSONQuotes, `"\/é😀\ud83d"`, `"\/é😀\ud83d"`, '"', "/é😀�", 0},
+		{JSONQuotes, `'a'`, "", 0, "", 0},
+		{JSONQuotes, "\"a\tb\"", "\"a\tb\"", '"', "ab", 3},
+		{JSONQuotes, `"\a"`, `"\a"`, '"', "", 2},
+		{CQuotes, `"\?\'\x0041\7\e"`, `"\?\'\x0041\7\e"`, '"', "?'A\a", 14},
+		{CQuotes, `'ab'`, `'ab'`, '\'', "ab", 0},
+		{SQLQuotes, `'it''s' x`, `'it''s'`, '\'', "it's", 0},
+		{SQLQuotes, `"a""b"`, `"a""b"`, '"', `a"b`, 0},
+		{SQLQuotes, `'a\'`, `'a\'`, '\'', `a\`, 0},
+		{SQLQuotes, `'a`, `'a`, '\'', "a", 1},
+		{RawQuotes, "'a\\'", "'a\\'", '\'', "a\\", 0},
+		{HeredocQuotes, "<<EOT\nline 1\n  EOT\nline 3\nEOT\nrest", "<<EOT\nline 1\n  EOT\nline 3\nEOT", '<', "line 1\n  EOT\nline 3", 0},
+		{HeredocQuotes, "<<EOT\r\nx\r\nEOT", "<<EOT\r\nx\r\nEOT", '<', "x", 0},
+		{HeredocQuotes, "<< EOT", "", 0, "", 0},
+		{HeredocQuotes, "<<EOT x\n", "<<EOT", '<', "", 6},
+		{HeredocQuotes, "<<EOT\nx\nEOTT", "<<EOT\nx\nEOTT", '<', "x\nEOTT", 1},
+	} {
+		var s Scanner
+		s.InitWithString(testCase.Input)
+		quote, value, err := s.ScanQuoted(testCase.Dialect)
+
+		var e *Error
+		if quote != testCase.Quote || s.Text() != testCase.Text || value != testCase.Value {
+			t.Errorf("%q: got %q %q %q", testCase.Input, quote, s.Text(), value)
+		} else if testCase.Column == 0 && err != nil {
+			t.Errorf("%q: unexpected error %s", testCase.Input, err)
+		} else if testCase.Column != 0 && (!errors.As(err, &e) || e.Column != testCase.Column) {
+			t.Errorf("%q: expected error at column %d, got %v", testCase.Input, testCase.Column, err)
+		}
+	}
+}
+
+func TestScanQuotedUnterminated(t *testing.T) {
+	var s Scanner
+	s.InitWithString("x = \"abc\ny")
+	s.Advance()
+	s.Advance()
+	s.Advance()
+	s.Advance()
+	s.Skip()
+
+	_, _, err := s.ScanQuoted(GoQuotes)
+	var e *Error
+	if !errors.As(err, &e) || !errors.Is(err, ErrUnterminatedLiteral) {
+		t.Fatal(err)
+	} else if e.Position != (Position{Offset: 4, Line: 1, Column: 5}) {
+		t.Fatal(e.Position)
+	} else if s.Peek() != '\n' {
+		t.Fatal("expected the literal to end at the end of the line")
+	}
+}
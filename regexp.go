@@ -0,0 +1,94 @@
+package prattle
+
+import (
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+)
+
+// ExpectRegexp advances the cursor over the match of re
+// if the input at the cursor begins with it.
+// An empty match reports true without advancing the cursor.
+//
+// The match is anchored at the cursor, so a failed match
+// does not search the remainder of the input.
+// If re does not begin with ^, ExpectRegexp anchors a copy of it
+// that is compiled once and uses leftmost-first matching even if re is Longest.
+func (s *Scanner) ExpectRegexp(re *regexp.Regexp) bool {
+	re = anchored(re)
+
+	var loc []int
+	switch r := s.reader.(type) {
+	case *stringSpanner:
+		loc = re.FindStringIndex(r.source[r.cursor:])
+	case *bytesSpanner:
+		loc = re.FindIndex(r.source[r.cursor:])
+	default:
+		m := s.Mark()
+		loc = re.FindReaderIndex(scannerRuneReader{s})
+		s.Reset(m)
+	}
+
+	if loc == nil || loc[0] != 0 {
+		return false
+	}
+
+	for end := s.cursor + loc[1]; s.cursor < end; {
+		s.Advance()
+	}
+	return true
+}
+
+// anchoredRegexps maps regular expressions to their anchored copies.
+var anchoredRegexps sync.Map
+
+// anchored returns re if it begins with ^ and otherwise an anchored copy of it.
+func anchored(re *regexp.Regexp) *regexp.Regexp {
+	if a, ok := anchoredRegexps.Load(re); ok {
+		return a.(*regexp.Regexp)
+	}
+
+	a := re
+	if !beginsText(re.String()) {
+		a = regexp.MustCompile(`^(?:` + re.String() + `)`)
+	}
+	anchoredRegexps.Store(re, a)
+	return a
+}
+
+// beginsText reports whether every match of expr begins with ^.
+func beginsText(expr string) bool {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	for {
+		switch re.Op {
+		case syntax.OpBeginText:
+			return true
+		case syntax.OpCapture, syntax.OpConcat:
+			if len(re.Sub) == 0 {
+				return false
+			}
+			re = re.Sub[0]
+		default:
+			return false
+		}
+	}
+}
+
+// scannerRuneReader reads runes by advancing the cursor of a Scanner.
+type scannerRuneReader struct {
+	s *Scanner
+}
+
+func (r scannerRuneReader) ReadRune() (rune, int, error) {
+	s := r.s
+	if s.Done() {
+		return 0, 0, io.EOF
+	}
+	c, size := s.peek, s.peekw
+	s.Advance()
+	return c, size, nil
+}
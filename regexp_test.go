@@ -0,0 +1,72 @@
+package prattle
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExpectRegexp(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}(-[0-9a-f]{4}){3}-[0-9a-f]{12}`)
+	block := regexp.MustCompile(`^(?s)«.*?»`)
+	empty := regexp.MustCompile(`^x*`)
+	unanchored := regexp.MustCompile(`«`)
+
+	source := "«é\nü» 123e4567-e89b-12d3-a456-426614174000 «"
+
+	for _, init := range []func(*Scanner) *Scanner{
+		func(s *Scanner) *Scanner { return s.InitWithString(source) },
+		func(s *Scanner) *Scanner { return s.InitWithBytes([]byte(source)) },
+		func(s *Scanner) *Scanner { return s.InitWithReader(strings.NewReader(source)) },
+	} {
+		s := init(&Scanner{})
+
+		if s.ExpectRegexp(uuid) || !s.ExpectRegexp(block) || s.Text() != "«é\nü»" {
+			t.Fatal(s.Text())
+		} else if pos := s.Cursor(); pos.Line != 2 || pos.Column != 3 {
+			t.Fatal(pos)
+		}
+
+		s.Skip()
+		if !s.ExpectRegexp(empty) || s.Text() != "" || s.ExpectRegexp(unanchored) {
+			t.Fatal(s.Text())
+		}
+
+		s.Advance()
+		s.Skip()
+		if !s.ExpectRegexp(uuid) || s.Text() != "123e4567-e89b-12d3-a456-426614174000" {
+			t.Fatal(s.Text())
+		} else if pos := s.Cursor(); pos.Line != 2 || pos.Column != 40 {
+			t.Fatal(pos)
+		}
+
+		s.Advance()
+		s.Skip()
+		if s.ExpectRegexp(block) || s.Peek() != '«' || !s.ExpectRegexp(unanchored) || !s.Done() {
+			t.Fatal(s.Text())
+		}
+	}
+}
+
+func TestAnchored(t *testing.T) {
+	for _, testCase := range []struct {
+		Expr     string
+		Anchored bool
+	}{
+		{`^a`, true},
+		{`(^a)b`, true},
+		{`^a|b`, false},
+		{`a^`, false},
+		{`(?m)^a`, false},
+		{``, false},
+	} {
+		re := regexp.MustCompile(testCase.Expr)
+		if a := anchored(re); (a == re) != testCase.Anchored {
+			t.Error(testCase.Expr, a)
+		} else if anchored(re) != a {
+			t.Error(testCase.Expr, "not cached")
+		} else if loc := a.FindStringIndex("ba"); loc != nil && loc[0] != 0 {
+			t.Error(testCase.Expr, loc)
+		}
+	}
+}
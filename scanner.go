@@ -2,7 +2,7 @@ package prattle
 
 import (
 	"io"
-	"strings"
+	"os"
 )
 
 // ScanFunc scans the next token and returns its kind.
@@ -12,63 +12,172 @@ type ScanFunc func(*Scanner) (kind int)
 // AcceptFunc accepts a rune.
 type AcceptFunc func(rune) bool
 
-// Scanner produces a stream of tokens from a string or an io.RuneReader.
+// Scanner produces a stream of tokens from a string, a byte slice or an io.Reader.
 type Scanner struct {
 	// Position of the last read token.
-	// The Filename field will never be modified by Scanner.
+	// The Filename field labels the positions of all tokens.
+	// It is set by the Init methods to the filename passed to them,
+	// or to the name of File if no filename is passed and File is not nil.
 	Position
 
 	// Scan scans tokens.
 	Scan ScanFunc
 
+	// Columns computes column numbers.
+	// Columns are counted in runes if Columns is nil.
+	// Column numbers start at 1 regardless, because a zero Column means
+	// that the column is unknown. Consumers that expect zero-based columns,
+	// such as the Language Server Protocol, use Position.ZeroBasedColumn.
+	Columns ColumnFunc
+
+	// LineEndings determines which line terminators advance the line number.
+	LineEndings LineEnding
+
+	// NormalizeLineEndings replaces the line terminators in Token.Text by "\n".
+	NormalizeLineEndings bool
+
+	// Trivia, if not nil, describes the whitespace and comments
+	// that are skipped before each call to Scan in mode zero.
+	// No trivia is skipped in other modes unless ModeTrivia is set,
+	// so that modes such as string literals keep their whitespace.
+	Trivia *Trivia
+
+	// ModeTrivia, if not nil, replaces Trivia and describes
+	// the trivia of each mode, indexed by mode.
+	// Modes without an entry skip no trivia.
+	ModeTrivia []*Trivia
+
+	// Lossless attaches the text that is skipped between tokens
+	// to the Leading and Trailing fields of the tokens,
+	// so that the input can be reproduced from the token stream.
+	Lossless bool
+
+	// File records the line offsets of the input if it is not nil.
+	// Use File.Pos to convert Token offsets to compact Pos values.
+	File *File
+
 	reader  spanReader
 	peek    rune
 	peekw   int
 	cursor  int
 	curline int
 	curcoln int
+	skips   int
+	err     error
+	leading []byte
+	quoted  []byte
+	modes   []int
 }
 
-func (s *Scanner) init(r spanReader) *Scanner {
-	s.Offset = 0
-	s.Column = 0
-	s.Line = 0
+// Mark is a checkpoint of the Scanner state created by Scanner.Mark.
+type Mark struct {
+	cursor  int
+	curline int
+	curcoln int
+	peek    rune
+	peekw   int
+	span    int
+	skips   int
+}
+
+func (s *Scanner) init(r spanReader, filename string) *Scanner {
 	s.reader = r
 	s.peek = 0
 	s.peekw = 0
 	s.cursor = 0
 	s.curline = 1
-	s.curcoln = 1
-	s.Advance()
+	s.curcoln = 0
+	s.err = nil
+	s.leading = s.leading[:0]
+	s.modes = s.modes[:0]
+	s.Offset = 0
+	s.Line = 1
+	s.Column = s.column()
+	s.Filename = filename
+	if filename == "" && s.File != nil {
+		s.Filename = s.File.Name()
+	}
+	s.read()
 	return s
 }
 
-// Init initializes a Scanner with an input string and returns it.
+// InitWithString initializes a Scanner with an input string and returns it.
 // When initialized this way, the Scanner tokenizes without allocations.
 func (s *Scanner) InitWithString(source string) *Scanner {
+	return s.InitWithNamedString("", source)
+}
+
+// InitWithNamedString is like InitWithString
+// and labels the positions of all tokens with filename.
+func (s *Scanner) InitWithNamedString(filename, source string) *Scanner {
 	// reuse existing stringSpanner if possible
 	if ss, ok := s.reader.(*stringSpanner); ok {
 		ss.source = source
 		ss.cursor = 0
 		ss.size = 0
-		return s.init(ss)
+		return s.init(ss, filename)
+	}
+
+	return s.init(&stringSpanner{source: source}, filename)
+}
+
+// InitWithBytes initializes a Scanner with an input byte slice and returns it.
+// When initialized this way, Bytes and NextBytes return sub-slices of the input,
+// so NextBytes tokenizes without allocations.
+// Next allocates a string for the text of every token.
+// The input must not be modified while it is being scanned.
+func (s *Scanner) InitWithBytes(source []byte) *Scanner {
+	return s.InitWithNamedBytes("", source)
+}
+
+// InitWithNamedBytes is like InitWithBytes
+// and labels the positions of all tokens with filename.
+func (s *Scanner) InitWithNamedBytes(filename string, source []byte) *Scanner {
+	// reuse existing bytesSpanner if possible
+	if bs, ok := s.reader.(*bytesSpanner); ok {
+		bs.source = source
+		bs.cursor = 0
+		bs.size = 0
+		return s.init(bs, filename)
+	}
+
+	return s.init(&bytesSpanner{source: source}, filename)
+}
+
+// InitWithFile reads the named file, initializes the Scanner with its contents
+// as if by InitWithNamedBytes and returns it.
+// The filename labels the positions of all tokens.
+func (s *Scanner) InitWithFile(filename string) (*Scanner, error) {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return s, err
 	}
+	return s.InitWithNamedBytes(filename, source), nil
+}
 
-	return s.init(&stringSpanner{source: source})
+// InitWithReader initializes a Scanner with an input reader and returns it.
+// When initialized this way, the Scanner tokenizes unbounded streams
+// through a reusable buffer and allocates only to build Token.Text.
+// Use NextBytes to tokenize without allocations.
+func (s *Scanner) InitWithReader(r io.Reader) *Scanner {
+	return s.InitWithNamedReader("", r)
 }
 
-// Init initializes a Scanner with an input reader and returns it.
-// When initialized this way, the Scanner tokenizes unbounded streams but must allocate.
-func (s *Scanner) InitWithReader(r io.RuneReader) *Scanner {
-	// reuse existing runeReaderSpanner if possible
-	if rrs, ok := s.reader.(*runeReaderSpanner); ok {
-		rrs.rr = r
-		rrs.buf = rrs.buf[:0]
-		rrs.r = 0
-		return s.init(rrs)
+// InitWithNamedReader is like InitWithReader
+// and labels the positions of all tokens with filename.
+func (s *Scanner) InitWithNamedReader(filename string, r io.Reader) *Scanner {
+	// reuse existing readerSpanner if possible
+	if rs, ok := s.reader.(*readerSpanner); ok {
+		rs.r = r
+		rs.buf = rs.buf[:0]
+		rs.start = 0
+		rs.cursor = 0
+		rs.size = 0
+		rs.err = nil
+		return s.init(rs, filename)
 	}
 
-	return s.init(&runeReaderSpanner{rr: r})
+	return s.init(&readerSpanner{r: r, buf: make([]byte, 0, 4096)}, filename)
 }
 
 // Text returns the token string that has been scanned so far.
@@ -76,50 +185,259 @@ func (s *Scanner) Text() string {
 	return s.reader.Span()
 }
 
+// Bytes returns the token text that has been scanned so far as a byte slice.
+// The slice may refer to the internal buffer of the Scanner
+// and is only valid until the cursor advances.
+func (s *Scanner) Bytes() []byte {
+	return s.reader.Bytes()
+}
+
 // Next implements Iterator.
 func (s *Scanner) Next() (Token, bool) {
-	var tok Token
-	tok.Kind = s.Scan(s)
-	tok.Text = s.Text()
-	tok.Position = s.Position
-	s.Skip()
+	tok, n := s.next()
+	text := s.Text()
+	tok.Text = text[:n]
+	if s.Lossless {
+		tok.Trailing = text[n:]
+	}
+	if s.NormalizeLineEndings {
+		tok.Text = s.LineEndings.normalize(tok.Text)
+	}
+	s.skip()
 	return tok, tok.Kind > 0
 }
 
+// NextBytes is like Next but returns the token text as a byte slice
+// instead of setting the Text field of the token.
+// The slice may refer to the internal buffer of the Scanner
+// and is only valid until the next call to Next or NextBytes.
+func (s *Scanner) NextBytes() (tok Token, text []byte, ok bool) {
+	tok, n := s.next()
+	text = s.Bytes()
+	if s.Lossless {
+		tok.Trailing = string(text[n:])
+	}
+	text = text[:n:n]
+	s.skip()
+	return tok, text, tok.Kind > 0
+}
+
+// next scans the next token and returns it without its text,
+// together with the length of the text in bytes.
+// In lossless mode, the span of the Scanner also covers the trailing trivia.
+func (s *Scanner) next() (tok Token, n int) {
+	tok.Kind = s.scan()
+	tok.Position = s.Position
+	tok.End = s.Cursor()
+	n = s.cursor - s.Offset
+	if s.Lossless {
+		tok.Leading = s.takeLeading()
+		s.scanTrailing()
+	}
+	return tok, n
+}
+
+// scan skips trivia and scans the next token.
+func (s *Scanner) scan() int {
+	if t := s.trivia(); t != nil {
+		if kind := s.skipTrivia(t); kind > 0 {
+			return kind
+		}
+	}
+	return s.Scan(s)
+}
+
 // Skip swallows the next token.
+// In lossless mode, the swallowed text becomes leading trivia of the next token.
 func (s *Scanner) Skip() {
+	if s.Lossless {
+		s.leading = append(s.leading, s.reader.Span()...)
+	}
+	s.skip()
+}
+
+func (s *Scanner) skip() {
 	s.Offset = s.cursor
 	s.Line = s.curline
-	s.Column = s.curcoln
+	s.Column = s.column()
+	s.skips++
 	s.reader.NextSpan()
 }
 
+// Mark returns a checkpoint of the current cursor position
+// that can be restored with Reset.
+// The checkpoint is valid until the next call to Skip.
+// It does not include the mode stack.
+func (s *Scanner) Mark() Mark {
+	return Mark{
+		cursor:  s.cursor,
+		curline: s.curline,
+		curcoln: s.curcoln,
+		peek:    s.peek,
+		peekw:   s.peekw,
+		span:    s.reader.Mark(),
+		skips:   s.skips,
+	}
+}
+
+// Reset rewinds the cursor to a checkpoint created by Mark,
+// which allows a ScanFunc to backtrack and try another alternative.
+// It panics if the token has been skipped since the checkpoint was created.
+func (s *Scanner) Reset(m Mark) {
+	if m.skips != s.skips {
+		panic("prattle: Reset called with stale Mark")
+	}
+	s.cursor = m.cursor
+	s.curline = m.curline
+	s.curcoln = m.curcoln
+	s.peek = m.peek
+	s.peekw = m.peekw
+	s.reader.Reset(m.span)
+}
+
+// Cursor returns the position of the current rune,
+// which is also the end position of the token scanned so far.
+func (s *Scanner) Cursor() Position {
+	return Position{
+		Filename: s.Filename,
+		Offset:   s.cursor,
+		Line:     s.curline,
+		Column:   s.column(),
+	}
+}
+
+// endsLine reports whether the current rune terminates a line.
+func (s *Scanner) endsLine() bool {
+	if s.peek == '\n' {
+		return true
+	} else if !s.LineEndings.isTerminator(s.peek) {
+		return false
+	}
+	// the line of "\r\n" ends at the "\n"
+	return s.peek != '\r' || s.PeekN(1) != '\n'
+}
+
+// nextColumn returns the zero-based column that follows the current rune.
+func (s *Scanner) nextColumn() int {
+	if s.Columns != nil {
+		return s.Columns(s.curcoln, s.peek, s.peekw)
+	}
+	return s.curcoln + 1
+}
+
+// column returns the current column number.
+func (s *Scanner) column() int {
+	return s.curcoln + 1
+}
+
+// Pos returns the compact position of Position in File,
+// or NoPos if File is nil.
+// Once Next returns, Position has moved past the token,
+// so use NextPos to obtain the Pos of every token.
+func (s *Scanner) Pos() Pos {
+	if s.File == nil {
+		return NoPos
+	}
+	return s.File.Pos(s.Offset)
+}
+
 // Peek returns the current rune.
 func (s *Scanner) Peek() rune {
 	return s.peek
 }
 
-// Done reports whether the entire input has been consumed.
+// PeekN returns the rune n positions ahead of the current rune
+// without advancing the cursor. PeekN(0) is equivalent to Peek.
+// It returns zero if the lookahead extends beyond the end of input.
+func (s *Scanner) PeekN(n int) rune {
+	if n <= 0 || s.peekw == 0 {
+		return s.peek
+	}
+	r, _ := s.reader.PeekRune(n)
+	return r
+}
+
+// PeekString reports whether the input starting at the current rune
+// begins with prefix, without advancing the cursor.
+func (s *Scanner) PeekString(prefix string) bool {
+	i := 0
+	for _, r := range prefix {
+		if s.PeekN(i) != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// ExpectString advances the cursor past prefix if the input begins with it.
+func (s *Scanner) ExpectString(prefix string) bool {
+	if !s.PeekString(prefix) {
+		return false
+	}
+	for range prefix {
+		s.Advance()
+	}
+	return true
+}
+
+// Done reports whether the entire input has been consumed
+// or reading the input failed.
 func (s *Scanner) Done() bool {
 	return s.peekw == 0
 }
 
 // Advance advances the cursor by one rune.
 func (s *Scanner) Advance() {
-	if s.peek == '\n' {
+	if s.peekw == 0 {
+		return
+	}
+
+	// only a few runes can terminate a line
+	if (s.peek <= '\r' || s.peek >= '\u0085') && s.endsLine() {
 		s.curline++
-		s.curcoln = 1
-	} else if s.peekw > 0 {
-		s.curcoln++
+		s.curcoln = 0
+		s.cursor += s.peekw
+		if s.File != nil {
+			s.File.AddLine(s.cursor)
+		}
+	} else {
+		col := s.nextColumn()
+		if s.File != nil && col-s.curcoln != s.peekw {
+			s.File.addColumn(s.cursor+s.peekw, col+1)
+		}
+		s.curcoln = col
+		s.cursor += s.peekw
 	}
 
-	s.cursor += s.peekw
-	s.peek, s.peekw, _ = s.reader.ReadRune()
-	if s.peekw == 0 {
+	s.read()
+}
+
+// read reads the next rune from the input.
+func (s *Scanner) read() {
+	var err error
+	if s.peek, s.peekw, err = s.reader.ReadRune(); s.peekw == 0 {
 		s.peek = 0
+		s.readError(err)
+	}
+}
+
+// readError records the first error other than io.EOF.
+func (s *Scanner) readError(err error) {
+	if err != nil && err != io.EOF && s.err == nil {
+		s.err = &Error{Position: s.Cursor(), Err: err}
 	}
 }
 
+// Err returns the first error other than io.EOF that was encountered
+// while reading the input or skipping trivia, or nil if there was none.
+// The error is an *Error that records where in the input it occurred.
+// The Scanner treats a read error as the end of input,
+// so Err should be checked once Done reports true.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
 // Expect advances the cursor if the current rune matches.
 func (s *Scanner) Expect(r rune) bool {
 	if s.Peek() == r {
@@ -130,8 +448,9 @@ func (s *Scanner) Expect(r rune) bool {
 }
 
 // ExpectOne advances the cursor if the current rune is accepted.
+// It returns false at the end of input.
 func (s *Scanner) ExpectOne(accept AcceptFunc) bool {
-	if accept(s.Peek()) {
+	if !s.Done() && accept(s.Peek()) {
 		s.Advance()
 		return true
 	}
@@ -139,15 +458,9 @@ func (s *Scanner) ExpectOne(accept AcceptFunc) bool {
 }
 
 // ExpectAny advances the cursor zero or more times.
+// It stops at the end of input.
 func (s *Scanner) ExpectAny(accept AcceptFunc) {
-	for accept(s.Peek()) {
+	for !s.Done() && accept(s.Peek()) {
 		s.Advance()
 	}
 }
-
-// OneOf returns an AcceptFunc that reports whether a rune appears in chars.
-func OneOf(chars string) AcceptFunc {
-	return func(r rune) bool {
-		return strings.ContainsRune(chars, r)
-	}
-}
@@ -1,8 +1,14 @@
 package prattle
 
 import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"unicode"
 )
 
@@ -29,13 +35,13 @@ func TestScanner(t *testing.T) {
 	}
 
 	expected := []Token{
-		{Kind: 1, Text: "result", Position: Position{Line: 1, Column: 1, Offset: 0}},
-		{Kind: 3, Text: "=", Position: Position{Line: 1, Column: 8, Offset: 7}},
-		{Kind: 4, Text: "€", Position: Position{Line: 2, Column: 1, Offset: 9}},
-		{Kind: 2, Text: "1337", Position: Position{Line: 2, Column: 2, Offset: 12}},
-		{Kind: 3, Text: "+", Position: Position{Line: 2, Column: 7, Offset: 17}},
-		{Kind: 1, Text: "BlAbLa", Position: Position{Line: 2, Column: 9, Offset: 19}},
-		{Position: Position{Line: 2, Column: 15, Offset: 25}},
+		{Kind: 1, Text: "result", Position: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 1, Column: 7, Offset: 6}},
+		{Kind: 3, Text: "=", Position: Position{Line: 1, Column: 8, Offset: 7}, End: Position{Line: 1, Column: 9, Offset: 8}},
+		{Kind: 4, Text: "€", Position: Position{Line: 2, Column: 1, Offset: 9}, End: Position{Line: 2, Column: 2, Offset: 12}},
+		{Kind: 2, Text: "1337", Position: Position{Line: 2, Column: 2, Offset: 12}, End: Position{Line: 2, Column: 6, Offset: 16}},
+		{Kind: 3, Text: "+", Position: Position{Line: 2, Column: 7, Offset: 17}, End: Position{Line: 2, Column: 8, Offset: 18}},
+		{Kind: 1, Text: "BlAbLa", Position: Position{Line: 2, Column: 9, Offset: 19}, End: Position{Line: 2, Column: 15, Offset: 25}},
+		{Position: Position{Line: 2, Column: 15, Offset: 25}, End: Position{Line: 2, Column: 15, Offset: 25}},
 	}
 
 	source := "result =\n€1337 + BlAbLa"
@@ -123,3 +129,254 @@ func Test_matchKeyword(t *testing.T) {
 		}
 	}
 }
+
+func TestScannerPeekN(t *testing.T) {
+	scan := func(s *Scanner) int {
+		s.ExpectAny(unicode.IsSpace)
+		s.Skip()
+		switch {
+		case s.Done():
+			return 0
+		case s.ExpectString("..."):
+			return 3
+		case s.ExpectString(".."):
+			return 2
+		case s.PeekN(1) == '≠' && s.PeekN(2) == 0:
+			s.Advance()
+			s.Advance()
+			return 4
+		case s.Expect('.'):
+			return 1
+		}
+		s.Advance()
+		return -1
+	}
+
+	source := ". .. ... .... x≠"
+	expected := []Token{
+		{Kind: 1, Text: ".", Position: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 1, Column: 2, Offset: 1}},
+		{Kind: 2, Text: "..", Position: Position{Line: 1, Column: 3, Offset: 2}, End: Position{Line: 1, Column: 5, Offset: 4}},
+		{Kind: 3, Text: "...", Position: Position{Line: 1, Column: 6, Offset: 5}, End: Position{Line: 1, Column: 9, Offset: 8}},
+		{Kind: 3, Text: "...", Position: Position{Line: 1, Column: 10, Offset: 9}, End: Position{Line: 1, Column: 13, Offset: 12}},
+		{Kind: 1, Text: ".", Position: Position{Line: 1, Column: 13, Offset: 12}, End: Position{Line: 1, Column: 14, Offset: 13}},
+		{Kind: 4, Text: "x≠", Position: Position{Line: 1, Column: 15, Offset: 14}, End: Position{Line: 1, Column: 17, Offset: 18}},
+		{Position: Position{Line: 1, Column: 17, Offset: 18}, End: Position{Line: 1, Column: 17, Offset: 18}},
+	}
+
+	for _, init := range []func(*Scanner) *Scanner{
+		func(s *Scanner) *Scanner { return s.InitWithString(source) },
+		func(s *Scanner) *Scanner { return s.InitWithBytes([]byte(source)) },
+		func(s *Scanner) *Scanner { return s.InitWithReader(strings.NewReader(source)) },
+	} {
+		s := init(&Scanner{Scan: scan})
+		if s.PeekN(0) != '.' || s.PeekN(2) != '.' || s.PeekN(3) != '.' || s.PeekN(100) != 0 {
+			t.Fatal("unexpected lookahead")
+		} else if !s.PeekString(". ..") || s.PeekString(". ...") {
+			t.Fatal("unexpected prefix match")
+		}
+		for _, x := range expected {
+			tok, _ := s.Next()
+			if tok != x {
+				t.Fatal(tok)
+			}
+		}
+	}
+}
+
+func TestScannerMarkReset(t *testing.T) {
+	scan := func(s *Scanner) int {
+		s.ExpectAny(unicode.IsSpace)
+		s.Skip()
+		if s.Done() {
+			return 0
+		}
+
+		// Try the keyword first and fall back to an identifier.
+		m := s.Mark()
+		if s.ExpectString("ans") && !unicode.IsLetter(s.Peek()) {
+			return 1
+		}
+		s.Reset(m)
+
+		if s.ExpectOne(unicode.IsLetter) {
+			s.ExpectAny(unicode.IsLetter)
+			return 2
+		}
+		s.Advance()
+		return -1
+	}
+
+	source := "ans an answer\nänsa ans"
+	expected := []Token{
+		{Kind: 1, Text: "ans", Position: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 1, Column: 4, Offset: 3}},
+		{Kind: 2, Text: "an", Position: Position{Line: 1, Column: 5, Offset: 4}, End: Position{Line: 1, Column: 7, Offset: 6}},
+		{Kind: 2, Text: "answer", Position: Position{Line: 1, Column: 8, Offset: 7}, End: Position{Line: 1, Column: 14, Offset: 13}},
+		{Kind: 2, Text: "änsa", Position: Position{Line: 2, Column: 1, Offset: 14}, End: Position{Line: 2, Column: 5, Offset: 19}},
+		{Kind: 1, Text: "ans", Position: Position{Line: 2, Column: 6, Offset: 20}, End: Position{Line: 2, Column: 9, Offset: 23}},
+		{Position: Position{Line: 2, Column: 9, Offset: 23}, End: Position{Line: 2, Column: 9, Offset: 23}},
+	}
+
+	for _, init := range []func(*Scanner) *Scanner{
+		func(s *Scanner) *Scanner { return s.InitWithString(source) },
+		func(s *Scanner) *Scanner { return s.InitWithBytes([]byte(source)) },
+		func(s *Scanner) *Scanner { return s.InitWithReader(strings.NewReader(source)) },
+	} {
+		s := init(&Scanner{Scan: scan})
+		for _, x := range expected {
+			tok, _ := s.Next()
+			if tok != x {
+				t.Fatal(tok)
+			}
+		}
+	}
+}
+
+func TestScannerResetStale(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+
+	var s Scanner
+	s.InitWithString("abc")
+	m := s.Mark()
+	s.Advance()
+	s.Skip()
+	s.Reset(m)
+}
+
+func TestScannerErr(t *testing.T) {
+	errBroken := errors.New("broken pipe")
+	r := bufio.NewReader(io.MultiReader(
+		strings.NewReader("abc\nde"),
+		iotest.ErrReader(errBroken),
+	))
+
+	s := (&Scanner{Scan: scanWords}).InitWithReader(r)
+
+	for _, x := range []string{"abc", "de", ""} {
+		if tok, _ := s.Next(); tok.Text != x {
+			t.Fatal(tok)
+		}
+	}
+
+	var err *Error
+	if !s.Done() || !errors.As(s.Err(), &err) || !errors.Is(err, errBroken) {
+		t.Fatal(s.Err())
+	} else if err.Position != (Position{Offset: 6, Line: 2, Column: 3}) {
+		t.Fatal(err.Position)
+	} else if err.Error() != "<input>:2,3: broken pipe" {
+		t.Fatal(err.Error())
+	}
+
+	s.InitWithReader(strings.NewReader("abc"))
+	if s.Err() != nil {
+		t.Fatal("expected no error after reinit")
+	}
+}
+
+func TestScannerReaderBuffer(t *testing.T) {
+	long := strings.Repeat("abä", 3000)
+	source := "xä " + long + " yz\n" + long
+
+	s := (&Scanner{Scan: scanWords}).InitWithReader(iotest.OneByteReader(strings.NewReader(source)))
+	if !s.PeekString("xä abä") || s.PeekN(3) != 'a' {
+		t.Fatal("unexpected lookahead")
+	}
+
+	for _, x := range []string{"xä", long, "yz", long} {
+		if tok, text, ok := s.NextBytes(); !ok || string(text) != x || tok.Text != "" {
+			t.Fatal(tok, len(text))
+		}
+	}
+
+	if _, _, ok := s.NextBytes(); ok || s.Err() != nil {
+		t.Fatal("expected end of input")
+	}
+}
+
+func TestScannerWithBytes(t *testing.T) {
+	source := []byte("hello world")
+	s := (&Scanner{Scan: scanWords}).InitWithBytes(source)
+	s.InitWithBytes(source)
+
+	tok, text, ok := s.NextBytes()
+	if !ok || tok.Offset != 0 || string(text) != "hello" {
+		t.Fatal(tok)
+	} else if &text[0] != &source[0] || cap(text) != len(text) {
+		t.Fatal("expected a capped sub-slice of the input")
+	}
+
+	if tok, _ = s.Next(); tok.Text != "world" || tok.Offset != 6 {
+		t.Fatal(tok)
+	}
+}
+
+func TestScannerMultilineToken(t *testing.T) {
+	scan := func(s *Scanner) int {
+		switch {
+		case s.Done():
+			return 0
+		case s.Expect('"'):
+			for !s.Done() && !s.Expect('"') {
+				s.Advance()
+			}
+			return 1
+		}
+		s.Advance()
+		return -1
+	}
+
+	s := (&Scanner{Scan: scan}).InitWithString("\"ab\nc€d\"")
+	tok, _ := s.Next()
+	if tok.End != (Position{Line: 2, Column: 5, Offset: 10}) {
+		t.Fatal(tok.End)
+	} else if tok.Range().String() != "<input>:1,1-2,5" {
+		t.Fatal(tok.Range())
+	}
+}
+
+func TestScannerWithFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.expr")
+	if err := os.WriteFile(filename, []byte("alpha\n  beta"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Scanner{Scan: scanWords}
+	if _, err := s.InitWithFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Next()
+	if tok, _ := s.Next(); tok.String() != filename+":2,3: 'beta'(1)" {
+		t.Fatal(tok)
+	}
+
+	// The other initializers do not reuse the filename.
+	if tok, _ := s.InitWithString("gamma").Next(); tok.Filename != "" {
+		t.Fatal(tok)
+	}
+
+	for _, init := range []func(*Scanner) *Scanner{
+		func(s *Scanner) *Scanner { return s.InitWithNamedString("a.expr", "gamma") },
+		func(s *Scanner) *Scanner { return s.InitWithNamedBytes("a.expr", []byte("gamma")) },
+		func(s *Scanner) *Scanner { return s.InitWithNamedReader("a.expr", strings.NewReader("gamma")) },
+	} {
+		if tok, _ := init(&s).Next(); tok.String() != "a.expr:1,1: 'gamma'(1)" {
+			t.Fatal(tok)
+		}
+	}
+
+	// The filename passed to an initializer takes precedence over File.
+	s.File = NewFileSet().AddFile("b.expr", 5)
+	if tok, _ := s.InitWithNamedString("a.expr", "gamma").Next(); tok.Filename != "a.expr" {
+		t.Fatal(tok)
+	} else if tok, _ := s.InitWithString("gamma").Next(); tok.Filename != "b.expr" {
+		t.Fatal(tok)
+	}
+
+	if _, err := s.InitWithFile(filename + ".missing"); err == nil {
+		t.Fatal("expected error")
+	}
+}
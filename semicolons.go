@@ -0,0 +1,200 @@
+package prattle
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SemicolonRule reports whether a statement terminator is inserted
+// between the tokens prev and next,
+// which are separated by a line break or the end of input.
+// At the end of input, next has kind zero.
+type SemicolonRule func(prev, next Token) bool
+
+// EndKinds returns a SemicolonRule that inserts a terminator
+// after tokens of the given kinds, which may end a statement.
+// This is the rule of Go if kinds lists the kinds of identifiers, literals,
+// the keywords break, continue, fallthrough and return,
+// and the operators ++, --, ), ] and }.
+func EndKinds(kinds ...int) SemicolonRule {
+	return func(prev, next Token) bool {
+		return containsKind(kinds, prev.Kind)
+	}
+}
+
+// ContinueKinds returns a SemicolonRule that inserts a terminator where rule does,
+// except before tokens of the given kinds, which continue the statement
+// of the previous line. JavaScript, for example, continues a statement
+// on a line that begins with ( or a binary operator.
+func ContinueKinds(rule SemicolonRule, kinds ...int) SemicolonRule {
+	return func(prev, next Token) bool {
+		return !containsKind(kinds, next.Kind) && rule(prev, next)
+	}
+}
+
+// Semicolons is an Iterator that inserts statement terminators
+// into the tokens read from another Iterator.
+// A terminator is inserted at a line break or at the end of input
+// when Rule reports true. Inserted terminators have no text
+// and are positioned at the end of the token before them.
+type Semicolons struct {
+	// Semicolon is the kind of the inserted tokens.
+	Semicolon int
+
+	// Rule decides where terminators are inserted.
+	Rule SemicolonRule
+
+	iter    Iterator
+	last    Token
+	next    Token
+	nextOK  bool
+	pending bool
+}
+
+// Init initializes Semicolons with an Iterator and returns it.
+func (sc *Semicolons) Init(iter Iterator) *Semicolons {
+	sc.iter = iter
+	sc.last = Token{}
+	sc.next = Token{}
+	sc.nextOK = false
+	sc.pending = false
+	return sc
+}
+
+// Next implements Iterator.
+func (sc *Semicolons) Next() (Token, bool) {
+	if sc.pending {
+		sc.pending = false
+		sc.last = sc.next
+		return sc.next, sc.nextOK
+	}
+
+	tok, ok := sc.iter.Next()
+
+	// tokens that do not come from a Scanner may have no end position
+	pos := sc.last.End
+	if !pos.IsValid() {
+		pos = sc.last.Position
+	}
+
+	if sc.last.Kind != 0 && sc.last.Kind != sc.Semicolon &&
+		(atEnd(tok, ok) || tok.Line > pos.Line) && sc.Rule(sc.last, tok) {
+		sc.next, sc.nextOK, sc.pending = tok, ok, true
+		sc.last = Token{Position: pos, End: pos, Kind: sc.Semicolon}
+		return sc.last, true
+	}
+
+	sc.last = tok
+	return tok, ok
+}
+
+// Err returns the error reported by the Err method
+// of the underlying Iterator, if it has one.
+func (sc *Semicolons) Err() error {
+	return iterErr(sc.iter)
+}
+
+func (sc *Semicolons) modes() (ModeStack, bool) {
+	if sc.pending {
+		return nil, false
+	}
+	return iterModes(sc.iter)
+}
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// GoSemicolons implements the semicolon rule of Go.
+// A terminator is inserted after a line's final token if that token is
+// an identifier, a literal, one of the keywords
+// break, continue, fallthrough and return,
+// or one of the operators ++, --, ), ] and }.
+//
+// Tokens are classified by their text rather than their kind,
+// because kinds are chosen by the ScanFunc and mean nothing to a preset.
+// Use EndKinds to apply the same rule by kind.
+func GoSemicolons(prev, next Token) bool {
+	switch prev.Text {
+	case "break", "continue", "fallthrough", "return", "++", "--", ")", "]", "}":
+		return true
+	}
+	return !goKeywords[prev.Text] && isOperand(prev.Text, false)
+}
+
+var jsKeywords = map[string]bool{
+	"case": true, "catch": true, "class": true, "const": true, "default": true,
+	"delete": true, "do": true, "else": true, "export": true, "extends": true,
+	"finally": true, "for": true, "function": true, "if": true, "import": true,
+	"in": true, "instanceof": true, "new": true, "switch": true, "throw": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true,
+	"with": true,
+}
+
+// JSSemicolons implements a line-based approximation of
+// the automatic semicolon insertion of JavaScript.
+// A terminator is inserted after a line's final token
+// if that token may end a statement and the next line
+// does not begin with a token that continues it, such as ( or a binary operator.
+// A line break after return, break, continue and yield always ends the statement,
+// and so does a line break before a ++ or -- operator.
+// A ++ or -- operator at the end of a line is taken to be postfix.
+//
+// Like GoSemicolons, it classifies tokens by their text.
+// ContinueKinds applies the continuation part of the rule by kind.
+func JSSemicolons(prev, next Token) bool {
+	switch prev.Text {
+	case "return", "break", "continue", "yield":
+		return true
+	case "this", "null", "true", "false", "super", "debugger", "++", "--", ")", "]", "}":
+	default:
+		if jsKeywords[prev.Text] || !isOperand(prev.Text, true) {
+			return false
+		}
+	}
+
+	switch text := next.Text; {
+	case text == "++" || text == "--":
+		return true
+	case text == "in" || text == "instanceof":
+		return false
+	case text == "" || text == "!" || text == "~":
+		return true
+	case strings.HasPrefix(text, "!="):
+		return false
+	case text[0] == '/':
+		return !isRegexpLiteral(text)
+	}
+	return !strings.ContainsRune("([.,?:=+-*%<>&|^`", rune(next.Text[0]))
+}
+
+// isOperand reports whether text is an identifier or a literal.
+func isOperand(text string, js bool) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	switch {
+	case text == "":
+		return false
+	case r == '_' || unicode.IsLetter(r) || isDecimal(r):
+		return true
+	case r == '.':
+		return len(text) > 1 && isDecimal(rune(text[1]))
+	case r == '"' || r == '\'' || r == '`':
+		return true
+	case js && r == '$':
+		return true
+	case js && r == '/':
+		return isRegexpLiteral(text)
+	}
+	return false
+}
+
+// isRegexpLiteral reports whether text is a JavaScript regular expression literal
+// rather than a division operator.
+func isRegexpLiteral(text string) bool {
+	return len(text) > 1 && text != "/=" && strings.Count(text, "/") >= 2
+}
@@ -0,0 +1,118 @@
+package prattle
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestSemicolons(t *testing.T) {
+	rules := []Rule{
+		ClassRule(0, unicode.IsSpace),
+		ClassRule(1, IsXIDContinue),
+		LiteralRule(2, ";"),
+		RegexpRule(1, regexp.MustCompile(`^"[^"\n]*"`)),
+	}
+	for _, op := range strings.Fields("++ -- + - ( ) { } [ ] . , = != ! :=") {
+		rules = append(rules, LiteralRule(1, op))
+	}
+
+	scanner := Scanner{Scan: Lexer(rules...)}
+
+	for _, testCase := range []struct {
+		Name   string
+		Rule   SemicolonRule
+		Source string
+		Want   string
+	}{
+		{"Go", GoSemicolons, "x := f(a,\n  b)\nif x {\n\treturn\n}\ny++", "x := f ( a , b ) ; if x { return ; } ; y ++ ;"},
+		{"GoExplicit", GoSemicolons, "a;\nb = \"s\" ;\n", "a ; b = \"s\" ;"},
+		{"GoKeywords", GoSemicolons, "func\nelse\nfallthrough\n", "func else fallthrough ;"},
+		{"JS", JSSemicolons, "a = b\n(c)\nreturn\nx\ni\n++j\nk = [1]\n.length\nm\n!n\no\n!= p", "a = b ( c ) ; return ; x ; i ; ++ j ; k = [ 1 ] . length ; m ; ! n ; o != p ;"},
+		{"JSKeywords", JSSemicolons, "if\nthis\nelse\n{}\n", "if this ; else { } ;"},
+		{"Empty", GoSemicolons, "\n\n", ""},
+	} {
+		t.Run(testCase.Name, func(t *testing.T) {
+			sc := Semicolons{Semicolon: 2, Rule: testCase.Rule}
+			sc.Init(scanner.InitWithString(testCase.Source))
+
+			var texts []string
+			for tok, ok := sc.Next(); ok; tok, ok = sc.Next() {
+				if tok.Kind == 2 {
+					texts = append(texts, ";")
+				} else {
+					texts = append(texts, tok.Text)
+				}
+			}
+
+			if got := strings.Join(texts, " "); got != testCase.Want {
+				t.Errorf("%q", got)
+			} else if sc.Err() != nil {
+				t.Error(sc.Err())
+			}
+		})
+	}
+
+	t.Run("Position", func(t *testing.T) {
+		sc := Semicolons{Semicolon: 2, Rule: GoSemicolons}
+		sc.Init(scanner.InitWithString("ab\ncd"))
+		sc.Next()
+		if tok, _ := sc.Next(); tok.Kind != 2 || tok.Line != 1 || tok.Column != 3 || tok.End != tok.Position {
+			t.Error(tok)
+		} else if tok, _ := sc.Next(); tok.Text != "cd" {
+			t.Error(tok)
+		}
+	})
+
+	t.Run("Kinds", func(t *testing.T) {
+		const (
+			ident int = 1 + iota
+			left
+			semicolon
+		)
+
+		tok := func(kind, line, column int, text string) Token {
+			return Token{Kind: kind, Text: text, Position: Position{Line: line, Column: column}}
+		}
+
+		// hand-built tokens have no end position
+		tokens := []Token{
+			tok(ident, 1, 1, "a"),
+			tok(ident, 1, 3, "b"),
+			tok(ident, 2, 1, "c"),
+			tok(left, 3, 1, "("),
+			tok(ident, 3, 2, "d"),
+		}
+
+		for _, testCase := range []struct {
+			Name string
+			Rule SemicolonRule
+			Want string
+		}{
+			{"Text", GoSemicolons, "a b ; c ; ( d ;"},
+			{"EndKinds", EndKinds(ident), "a b ; c ; ( d ;"},
+			{"ContinueKinds", ContinueKinds(EndKinds(ident), left), "a b ; c ( d ;"},
+		} {
+			sc := Semicolons{Semicolon: semicolon, Rule: testCase.Rule}
+			sc.Init(SliceIterator(tokens))
+
+			var texts []string
+			for tok, ok := sc.Next(); ok; tok, ok = sc.Next() {
+				if tok.Kind == semicolon {
+					// positioned at the token before it
+					if len(texts) == 2 && tok.Position != tokens[1].Position {
+						t.Error(tok)
+					}
+					texts = append(texts, ";")
+				} else {
+					texts = append(texts, tok.Text)
+				}
+			}
+
+			if got := strings.Join(texts, " "); got != testCase.Want {
+				t.Errorf("%s: %q", testCase.Name, got)
+			}
+		}
+	})
+}
@@ -7,8 +7,12 @@ import (
 
 type spanReader interface {
 	io.RuneReader
+	PeekRune(n int) (r rune, size int)
 	Span() string
+	Bytes() []byte
 	NextSpan()
+	Mark() int
+	Reset(mark int)
 }
 
 type stringSpanner struct {
@@ -24,32 +28,171 @@ func (s *stringSpanner) ReadRune() (r rune, size int, err error) {
 	return
 }
 
+func (s *stringSpanner) PeekRune(n int) (r rune, size int) {
+	i := s.cursor + s.size
+	for ; n > 0; n-- {
+		if r, size = utf8.DecodeRuneInString(s.source[i:]); size == 0 {
+			return 0, 0
+		}
+		i += size
+	}
+	return
+}
+
 func (s *stringSpanner) Span() string {
 	return s.source[:s.cursor]
 }
 
+func (s *stringSpanner) Bytes() []byte {
+	return []byte(s.Span())
+}
+
 func (s *stringSpanner) NextSpan() {
 	s.source = s.source[s.cursor:]
 	s.cursor = 0
 }
 
-type runeReaderSpanner struct {
-	rr  io.RuneReader
-	buf []rune
-	r   rune
+func (s *stringSpanner) Mark() int {
+	return s.cursor
+}
+
+func (s *stringSpanner) Reset(mark int) {
+	s.cursor = mark
+	_, s.size = utf8.DecodeRuneInString(s.source[mark:])
+}
+
+type bytesSpanner struct {
+	source []byte
+	cursor int
+	size   int
+}
+
+func (s *bytesSpanner) ReadRune() (r rune, size int, err error) {
+	s.cursor += s.size
+	r, size = utf8.DecodeRune(s.source[s.cursor:])
+	s.size = size
+	return
+}
+
+func (s *bytesSpanner) PeekRune(n int) (r rune, size int) {
+	i := s.cursor + s.size
+	for ; n > 0; n-- {
+		if r, size = utf8.DecodeRune(s.source[i:]); size == 0 {
+			return 0, 0
+		}
+		i += size
+	}
+	return
+}
+
+func (s *bytesSpanner) Span() string {
+	return string(s.source[:s.cursor])
+}
+
+func (s *bytesSpanner) Bytes() []byte {
+	return s.source[:s.cursor:s.cursor]
+}
+
+func (s *bytesSpanner) NextSpan() {
+	s.source = s.source[s.cursor:]
+	s.cursor = 0
+}
+
+func (s *bytesSpanner) Mark() int {
+	return s.cursor
 }
 
-func (s *runeReaderSpanner) ReadRune() (r rune, size int, err error) {
-	s.buf = append(s.buf, s.r)
-	r, size, err = s.rr.ReadRune()
-	s.r = r
+func (s *bytesSpanner) Reset(mark int) {
+	s.cursor = mark
+	_, s.size = utf8.DecodeRune(s.source[mark:])
+}
+
+// maxEmptyReads is the number of consecutive empty reads
+// after which readerSpanner gives up with io.ErrNoProgress.
+const maxEmptyReads = 100
+
+type readerSpanner struct {
+	r      io.Reader
+	buf    []byte
+	start  int
+	cursor int
+	size   int
+	err    error
+}
+
+// fill ensures that a full rune is buffered at cursor+i unless the reader is exhausted.
+// The window buf[start:] may be moved to the front of buf,
+// so indices are relative to the cursor.
+func (s *readerSpanner) fill(i int) {
+	for s.err == nil && !utf8.FullRune(s.buf[s.cursor+i:]) {
+		if s.start > 0 {
+			n := copy(s.buf, s.buf[s.start:])
+			s.buf = s.buf[:n]
+			s.cursor -= s.start
+			s.start = 0
+		}
+
+		if len(s.buf) == cap(s.buf) {
+			buf := make([]byte, len(s.buf), 2*cap(s.buf)+4096)
+			copy(buf, s.buf)
+			s.buf = buf
+		}
+
+		for empty := 0; ; empty++ {
+			n, err := s.r.Read(s.buf[len(s.buf):cap(s.buf)])
+			s.buf = s.buf[:len(s.buf)+n]
+			if err != nil {
+				s.err = err
+				break
+			} else if n > 0 {
+				break
+			} else if empty == maxEmptyReads {
+				s.err = io.ErrNoProgress
+				break
+			}
+		}
+	}
+}
+
+func (s *readerSpanner) ReadRune() (r rune, size int, err error) {
+	s.cursor += s.size
+	s.fill(0)
+	r, size = utf8.DecodeRune(s.buf[s.cursor:])
+	if s.size = size; size == 0 {
+		err = s.err
+	}
+	return
+}
+
+func (s *readerSpanner) PeekRune(n int) (r rune, size int) {
+	i := s.size
+	for ; n > 0; n-- {
+		s.fill(i)
+		if r, size = utf8.DecodeRune(s.buf[s.cursor+i:]); size == 0 {
+			return 0, 0
+		}
+		i += size
+	}
 	return
 }
 
-func (s *runeReaderSpanner) Span() string {
-	return string(s.buf)
+func (s *readerSpanner) Span() string {
+	return string(s.buf[s.start:s.cursor])
+}
+
+func (s *readerSpanner) Bytes() []byte {
+	return s.buf[s.start:s.cursor]
+}
+
+func (s *readerSpanner) NextSpan() {
+	s.start = s.cursor
+}
+
+func (s *readerSpanner) Mark() int {
+	return s.cursor - s.start
 }
 
-func (s *runeReaderSpanner) NextSpan() {
-	s.buf = s.buf[:0]
+func (s *readerSpanner) Reset(mark int) {
+	s.cursor = s.start + mark
+	_, s.size = utf8.DecodeRune(s.buf[s.cursor:])
 }
@@ -14,6 +14,8 @@ type Position struct {
 	Line int
 
 	// Column is the column number, starting at 1 (character count per line).
+	// Scanner can be configured to count columns differently.
+	// A Column of 0 means that the column is unknown.
 	Column int
 }
 
@@ -23,6 +25,12 @@ func (p Position) IsValid() bool {
 	return p.Line > 0
 }
 
+// ZeroBasedColumn returns the column number starting at 0,
+// or -1 if the column is unknown.
+func (p Position) ZeroBasedColumn() int {
+	return p.Column - 1
+}
+
 // String implements fmt.Stringer.
 func (p Position) String() string {
 	filename := p.Filename
@@ -38,18 +46,84 @@ func (p Position) String() string {
 	return filename
 }
 
+// Range represents a range of the input from Start up to but not including End.
+type Range struct {
+	Start Position
+	End   Position
+}
+
+// Contains reports whether a Position lies within the Range.
+func (r Range) Contains(p Position) bool {
+	return p.Filename == r.Start.Filename &&
+		r.Start.Offset <= p.Offset && p.Offset < r.End.Offset
+}
+
+// Union returns the smallest Range that covers both r and other.
+func (r Range) Union(other Range) Range {
+	if other.Start.Offset < r.Start.Offset {
+		r.Start = other.Start
+	}
+	if other.End.Offset > r.End.Offset {
+		r.End = other.End
+	}
+	return r
+}
+
+// String implements fmt.Stringer.
+func (r Range) String() string {
+	if !r.Start.IsValid() || !r.End.IsValid() {
+		return r.Start.String()
+	}
+	return fmt.Sprintf("%s-%d,%d", r.Start, r.End.Line, r.End.Column)
+}
+
 // Token is a fragment of tokenised text.
 type Token struct {
 	Position
 
+	// End is the position immediately after the last character of the token.
+	End Position
+
 	// Kind identifies the kind of token.
 	Kind int
 
 	// Text is the token value.
 	Text string
+
+	// Leading is the whitespace and comments that precede the token.
+	// It is only set by a Scanner in lossless mode.
+	Leading string
+
+	// Trailing is the whitespace and comments that follow the token
+	// up to the end of the line.
+	// It is only set by a Scanner in lossless mode.
+	Trailing string
+}
+
+// Range returns the Range of input covered by the token.
+func (t Token) Range() Range {
+	return Range{Start: t.Position, End: t.End}
 }
 
 // String implements fmt.Stringer.
 func (t Token) String() string {
 	return fmt.Sprintf("%s: '%s'(%d)", t.Position, t.Text, t.Kind)
 }
+
+// Error is an error that occurred at a position in the input.
+type Error struct {
+	Position
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Position, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -39,6 +39,19 @@ func TestPositionString(t *testing.T) {
 	}
 }
 
+func TestPositionZeroBasedColumn(t *testing.T) {
+	s := Scanner{Scan: scanWords, Columns: UTF16Columns}
+	s.InitWithString("😀 a")
+	s.Next()
+	if tok, _ := s.Next(); tok.ZeroBasedColumn() != 3 || tok.End.ZeroBasedColumn() != 4 {
+		t.Error(tok.Position, tok.End)
+	}
+
+	if (Position{Line: 1}).ZeroBasedColumn() != -1 {
+		t.Error()
+	}
+}
+
 func TestTokenString(t *testing.T) {
 	tok := Token{
 		Position: Position{
@@ -54,3 +67,45 @@ func TestTokenString(t *testing.T) {
 		t.Error()
 	}
 }
+
+func TestRange(t *testing.T) {
+	a := Range{
+		Start: Position{Filename: "a", Offset: 2, Line: 1, Column: 3},
+		End:   Position{Filename: "a", Offset: 5, Line: 1, Column: 6},
+	}
+	b := Range{
+		Start: Position{Filename: "a", Offset: 8, Line: 2, Column: 1},
+		End:   Position{Filename: "a", Offset: 12, Line: 2, Column: 5},
+	}
+
+	u := a.Union(b)
+	if u.Start != a.Start || u.End != b.End || b.Union(a) != u {
+		t.Fatal(u)
+	} else if u.String() != "a:1,3-2,5" {
+		t.Fatal(u.String())
+	}
+
+	for _, testCase := range []struct {
+		Pos    Position
+		Expect bool
+	}{
+		{Position{Filename: "a", Offset: 1}, false},
+		{Position{Filename: "a", Offset: 2}, true},
+		{Position{Filename: "a", Offset: 4}, true},
+		{Position{Filename: "a", Offset: 5}, false},
+		{Position{Filename: "b", Offset: 3}, false},
+	} {
+		if a.Contains(testCase.Pos) != testCase.Expect {
+			t.Error(testCase.Pos.Offset)
+		}
+	}
+
+	if (Range{}).String() != "<input>" {
+		t.Error()
+	}
+
+	tok := Token{Position: a.Start, End: a.End}
+	if tok.Range() != a {
+		t.Error()
+	}
+}
@@ -0,0 +1,113 @@
+package prattle
+
+import "errors"
+
+// ErrUnterminatedComment is reported by Scanner.Err
+// when the input ends inside a block comment.
+var ErrUnterminatedComment error = errors.New("unterminated comment")
+
+// BlockComment describes the delimiters of a comment that may span multiple lines.
+type BlockComment struct {
+	// Open is the opening delimiter, such as "/*".
+	Open string
+
+	// Close is the closing delimiter, such as "*/".
+	Close string
+
+	// Nested reports whether comments may be nested.
+	Nested bool
+}
+
+// Trivia describes the whitespace and comments that Scanner skips
+// before each call to Scan.
+type Trivia struct {
+	// Space accepts whitespace. No whitespace is skipped if Space is nil.
+	Space AcceptFunc
+
+	// LineComments lists the prefixes of comments
+	// that extend up to the end of the line, such as "//".
+	// Empty prefixes are ignored.
+	LineComments []string
+
+	// BlockComments lists the delimiters of block comments.
+	// Block comments with an empty Open or Close delimiter are ignored.
+	BlockComments []BlockComment
+
+	// SpaceKind, if positive, emits whitespace as tokens of this kind
+	// instead of discarding it.
+	SpaceKind int
+
+	// CommentKind, if positive, emits comments as tokens of this kind
+	// instead of discarding them.
+	CommentKind int
+}
+
+// trivia returns the Trivia of the current mode.
+func (s *Scanner) trivia() *Trivia {
+	mode := s.Mode()
+	if s.ModeTrivia == nil {
+		if mode == 0 {
+			return s.Trivia
+		}
+		return nil
+	} else if mode >= 0 && mode < len(s.ModeTrivia) {
+		return s.ModeTrivia[mode]
+	}
+	return nil
+}
+
+// skipTrivia skips whitespace and comments until it encounters
+// another token or trivia that must be emitted.
+// It returns the kind of emitted trivia or zero.
+func (s *Scanner) skipTrivia(t *Trivia) int {
+	for !s.Done() {
+		if kind := s.scanTrivia(t); kind < 0 {
+			return 0
+		} else if kind > 0 {
+			return kind
+		}
+		s.Skip()
+	}
+	return 0
+}
+
+// scanTrivia scans one run of whitespace or one comment.
+// It returns the kind to emit it as, zero to discard it,
+// or -1 if the input does not begin with trivia.
+func (s *Scanner) scanTrivia(t *Trivia) int {
+	if t.Space != nil && s.ExpectOne(t.Space) {
+		s.ExpectAny(t.Space)
+		return t.SpaceKind
+	}
+
+	for _, prefix := range t.LineComments {
+		if prefix != "" && s.ExpectString(prefix) {
+			for !s.Done() && !s.LineEndings.isTerminator(s.peek) {
+				s.Advance()
+			}
+			return t.CommentKind
+		}
+	}
+
+	for _, bc := range t.BlockComments {
+		if start := s.Cursor(); bc.Open != "" && bc.Close != "" && s.ExpectString(bc.Open) {
+			for depth := 1; depth > 0; {
+				if s.Done() {
+					if s.err == nil {
+						s.err = &Error{Position: start, Err: ErrUnterminatedComment}
+					}
+					break
+				} else if bc.Nested && s.ExpectString(bc.Open) {
+					depth++
+				} else if s.ExpectString(bc.Close) {
+					depth--
+				} else {
+					s.Advance()
+				}
+			}
+			return t.CommentKind
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,103 @@
+package prattle
+
+import (
+	"errors"
+	"testing"
+	"unicode"
+)
+
+func scanLetters(s *Scanner) int {
+	switch {
+	case s.Done():
+		return 0
+	case s.ExpectOne(unicode.IsLetter):
+		s.ExpectAny(unicode.IsLetter)
+		return 1
+	}
+	s.Advance()
+	return -1
+}
+
+func TestTrivia(t *testing.T) {
+	trivia := Trivia{
+		Space:        unicode.IsSpace,
+		LineComments: []string{"//", "#"},
+		BlockComments: []BlockComment{
+			{Open: "/*", Close: "*/", Nested: true},
+			{Open: "{-", Close: "-}"},
+		},
+	}
+
+	source := "a // b\nc#d\r\n/* e /* f */ g */h {- {- i -}j"
+
+	t.Run("Discard", func(t *testing.T) {
+		s := Scanner{Scan: scanLetters, Trivia: &trivia}
+		s.InitWithString(source)
+		for _, x := range []string{"a", "c", "h", "j", ""} {
+			if tok, _ := s.Next(); tok.Text != x {
+				t.Fatal(tok)
+			}
+		}
+		if s.Err() != nil {
+			t.Fatal(s.Err())
+		}
+	})
+
+	t.Run("Emit", func(t *testing.T) {
+		emit := trivia
+		emit.SpaceKind = 2
+		emit.CommentKind = 3
+		s := Scanner{Scan: scanLetters, Trivia: &emit}
+		s.InitWithString(source)
+		for _, x := range []Token{
+			{Kind: 1, Text: "a"},
+			{Kind: 2, Text: " "},
+			{Kind: 3, Text: "// b"},
+			{Kind: 2, Text: "\n"},
+			{Kind: 1, Text: "c"},
+			{Kind: 3, Text: "#d\r"},
+			{Kind: 2, Text: "\n"},
+			{Kind: 3, Text: "/* e /* f */ g */"},
+			{Kind: 1, Text: "h"},
+			{Kind: 2, Text: " "},
+			{Kind: 3, Text: "{- {- i -}"},
+			{Kind: 1, Text: "j"},
+			{},
+		} {
+			if tok, _ := s.Next(); tok.Kind != x.Kind || tok.Text != x.Text {
+				t.Fatal(tok)
+			}
+		}
+	})
+
+	t.Run("Unterminated", func(t *testing.T) {
+		s := Scanner{Scan: scanLetters, Trivia: &trivia}
+		s.InitWithString("a\n /* b /* c */")
+		for _, x := range []string{"a", ""} {
+			if tok, _ := s.Next(); tok.Text != x {
+				t.Fatal(tok)
+			}
+		}
+
+		var err *Error
+		if !errors.As(s.Err(), &err) || err.Err != ErrUnterminatedComment {
+			t.Fatal(s.Err())
+		} else if err.Line != 2 || err.Column != 2 {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("EmptyDelimiters", func(t *testing.T) {
+		empty := Trivia{
+			LineComments:  []string{""},
+			BlockComments: []BlockComment{{Open: "", Close: "x"}, {Open: "a", Close: ""}},
+		}
+		s := Scanner{Scan: scanLetters, Trivia: &empty}
+		s.InitWithString("a\nb")
+		for _, x := range []string{"a", "\n", "b", ""} {
+			if tok, _ := s.Next(); tok.Text != x {
+				t.Fatal(tok)
+			}
+		}
+	})
+}